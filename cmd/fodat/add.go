@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/wipe2238/fo/cmd"
+	"github.com/wipe2238/fo/dat"
+)
+
+const errUpdate = "update:"
+
+var optionsAdd = struct {
+	Compress string
+	MinRatio float64
+}{}
+
+func init() {
+	var cmdAdd = &cobra.Command{
+		Use:   "add <dat file> <input directory|@fileslist>...",
+		Short: "Add or replace files in DAT2 file",
+		Long: "Add or replace files in DAT2 file\n\n" +
+			"Input is handled same as by 'pack' command. New content is appended to DAT file,\n" +
+			"content of replaced files is left as unused space; use 'repack' command to remove it",
+
+		GroupID: app.GroupID,
+		Args:    cobra.MinimumNArgs(2),
+		RunE:    runAdd,
+	}
+
+	cmdAdd.Flags().StringVar(&optionsAdd.Compress, "compress", "auto", "Compression mode (auto, always, never)")
+	cmdAdd.Flags().Float64Var(&optionsAdd.MinRatio, "min-ratio", 0, "Minimal compression ratio (real size / packed size) required to store compressed data, used by 'auto' compression mode")
+
+	app.AddCommand(cmdAdd)
+}
+
+func runAdd(cmdAdd *cobra.Command, args []string) (err error) {
+	if err = cmd.ResolveFilenameWritable(&args[0], "@"); err != nil {
+		return err
+	}
+
+	var (
+		options dat.WriterOptions
+		files   []dat.WriterFile
+	)
+
+	if options, err = packOptions(2, optionsAdd.Compress, optionsAdd.MinRatio); err != nil {
+		return err
+	}
+
+	for _, arg := range args[1:] {
+		var filesArg []dat.WriterFile
+
+		if strings.HasPrefix(arg, "@") {
+			filesArg, err = packFilesList(filepath.Clean(arg[1:]))
+		} else {
+			filesArg, err = packFilesDir(filepath.Clean(arg), "")
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s %w", errUpdate, err)
+		}
+
+		files = append(files, filesArg...)
+	}
+
+	return doUpdate(cmdAdd.OutOrStdout(), args[0], files, nil, options)
+}
+
+// doUpdate opens DAT2 file for writing, and updates it in place
+func doUpdate(out io.Writer, filename string, files []dat.WriterFile, remove []string, options dat.WriterOptions) (err error) {
+	var (
+		osFile  *os.File
+		datFile dat.FalloutDat
+		size    int64
+	)
+
+	if osFile, err = os.OpenFile(filename, os.O_RDWR, 0); err != nil {
+		return err
+	}
+	defer osFile.Close()
+
+	if datFile, err = dat.Fallout2(osFile); err != nil {
+		return fmt.Errorf("%s only DAT2 files can be updated: %w", errUpdate, err)
+	}
+
+	if datFile, err = dat.UpdateFallout2(osFile, datFile, files, remove, options); err != nil {
+		return err
+	}
+
+	if size, err = osFile.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+
+	var count int
+	for _, dir := range datFile.GetDirs() {
+		count += len(dir.GetFiles())
+	}
+
+	fmt.Fprintf(out, "%s: %d file(s) added/replaced, %d file(s) removed, %d file(s) total, %d bytes\n", filename, len(files), len(remove), count, size)
+
+	return osFile.Close()
+}
@@ -47,6 +47,9 @@ func run() error {
 		Title: "General Commands:",
 	})
 
+	// Remove files extracted by `@dat:` pseudo-filenames
+	defer cmd.ResolveCleanup()
+
 	return app.Execute()
 }
 
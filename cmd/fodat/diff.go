@@ -0,0 +1,277 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/wipe2238/fo/cmd"
+	"github.com/wipe2238/fo/dat"
+)
+
+const errDiff = "diff:"
+
+var optionsDiff = struct {
+	Content bool
+}{}
+
+// diffTextExt lists extensions of files which can be compared line by line
+var diffTextExt = []string{".cfg", ".gam", ".ini", ".lst", ".msg", ".txt"}
+
+// diffLinesMax limits size of table used to find differences between lines,
+// files with more differing lines are reported as completely replaced
+const diffLinesMax = 1 << 22
+
+// diffHunk is a single block of changed lines; `StartA` and `StartB` are zero-based indexes
+type diffHunk struct {
+	StartA, StartB int
+	LinesA, LinesB []string
+}
+
+// diffDat is a single side of comparison
+type diffDat struct {
+	osFile  *os.File
+	datFile dat.FalloutDat
+	files   map[string]dat.FalloutFile // keys are lowercased
+}
+
+func init() {
+	var cmdDiff = &cobra.Command{
+		Use:   "diff <dat file A> <dat file B>",
+		Short: "Compare content of two DAT files",
+		Long: "Compare content of two DAT files\n\n" +
+			"Each file which differs between DAT files is reported in a single line, starting with\n" +
+			"  A (added, present in B only), D (deleted, present in A only), M (modified)\n\n" +
+			"Files are compared by path (case-insensitive), size, packing mode, and SHA-256 of unpacked content",
+
+		GroupID: app.GroupID,
+		Args:    cobra.ExactArgs(2),
+		RunE:    runDiff,
+	}
+
+	cmdDiff.Flags().BoolVar(&optionsDiff.Content, "content", false, "Show changed lines of modified text files ("+strings.Join(diffTextExt, ", ")+")")
+
+	app.AddCommand(cmdDiff)
+}
+
+func runDiff(cmdDiff *cobra.Command, args []string) (err error) {
+	var datA, datB diffDat
+
+	for idx, side := range []*diffDat{&datA, &datB} {
+		if err = cmd.ResolveFilename(&args[idx], "@"); err != nil {
+			return err
+		}
+
+		if side.osFile, side.datFile, err = dat.Open(args[idx]); err != nil {
+			return err
+		}
+		defer side.osFile.Close()
+
+		side.files = make(map[string]dat.FalloutFile)
+		for _, dir := range side.datFile.GetDirs() {
+			for _, file := range dir.GetFiles() {
+				side.files[strings.ToLower(path.Clean(file.GetPath()))] = file
+			}
+		}
+	}
+
+	return doDiff(cmdDiff.OutOrStdout(), datA, datB)
+}
+
+func doDiff(out io.Writer, datA diffDat, datB diffDat) (err error) {
+	var names = make([]string, 0, max(len(datA.files), len(datB.files)))
+	for name := range datA.files {
+		names = append(names, name)
+	}
+	for name := range datB.files {
+		if _, ok := datA.files[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	var added, deleted, modified int
+
+	for _, name := range names {
+		var fileA, okA = datA.files[name]
+		var fileB, okB = datB.files[name]
+
+		if !okA {
+			fmt.Fprintf(out, "A %s\n", path.Clean(fileB.GetPath()))
+			added++
+			continue
+		} else if !okB {
+			fmt.Fprintf(out, "D %s\n", path.Clean(fileA.GetPath()))
+			deleted++
+			continue
+		}
+
+		var (
+			changes        []string
+			bytesA, bytesB []byte
+		)
+
+		if fileA.GetSizeReal() != fileB.GetSizeReal() {
+			changes = append(changes, fmt.Sprintf("size %d → %d", fileA.GetSizeReal(), fileB.GetSizeReal()))
+		}
+
+		// Packing modes can be compared only between .dat files of same type
+		if datA.datFile.GetGame() == datB.datFile.GetGame() && fileA.GetPackedMode() != fileB.GetPackedMode() {
+			changes = append(changes, fmt.Sprintf("packed mode %d → %d", fileA.GetPackedMode(), fileB.GetPackedMode()))
+		} else if fileA.GetPacked() != fileB.GetPacked() {
+			changes = append(changes, fmt.Sprintf("packed %t → %t", fileA.GetPacked(), fileB.GetPacked()))
+		}
+
+		if bytesA, err = fileA.GetBytesRealAt(datA.osFile); err != nil {
+			return fmt.Errorf("%s %w", errDiff, err)
+		} else if bytesB, err = fileB.GetBytesRealAt(datB.osFile); err != nil {
+			return fmt.Errorf("%s %w", errDiff, err)
+		}
+
+		var hashA, hashB = sha256.Sum256(bytesA), sha256.Sum256(bytesB)
+		if hashA != hashB {
+			changes = append(changes, fmt.Sprintf("sha256 %x → %x", hashA[:4], hashB[:4]))
+		}
+
+		if len(changes) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(out, "M %s (%s)\n", path.Clean(fileB.GetPath()), strings.Join(changes, ", "))
+		modified++
+
+		if optionsDiff.Content && hashA != hashB && slices.Contains(diffTextExt, strings.ToLower(path.Ext(name))) {
+			doDiffContent(out, path.Clean(fileA.GetPath()), path.Clean(fileB.GetPath()), bytesA, bytesB)
+		}
+	}
+
+	fmt.Fprintf(out, "%d added, %d deleted, %d modified\n", added, deleted, modified)
+
+	return nil
+}
+
+// doDiffContent prints changed lines in unified format, without context lines
+func doDiffContent(out io.Writer, nameA string, nameB string, bytesA []byte, bytesB []byte) {
+	var lines = func(data []byte) (result []string) {
+		if len(data) == 0 {
+			return nil
+		}
+
+		result = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		for idx := range result {
+			result[idx] = strings.TrimSuffix(result[idx], "\r")
+		}
+
+		return result
+	}
+
+	// Empty range uses number of line before change, same as `diff -U0`
+	var lineRange = func(start int, count int) string {
+		switch count {
+		case 0:
+			return fmt.Sprintf("%d,0", start)
+		case 1:
+			return fmt.Sprintf("%d", start+1)
+		}
+
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+
+	fmt.Fprintf(out, "--- a/%s\n+++ b/%s\n", nameA, nameB)
+
+	for _, hunk := range diffLines(lines(bytesA), lines(bytesB)) {
+		fmt.Fprintf(out, "@@ -%s +%s @@\n", lineRange(hunk.StartA, len(hunk.LinesA)), lineRange(hunk.StartB, len(hunk.LinesB)))
+
+		for _, line := range hunk.LinesA {
+			fmt.Fprintf(out, "-%s\n", line)
+		}
+
+		for _, line := range hunk.LinesB {
+			fmt.Fprintf(out, "+%s\n", line)
+		}
+	}
+}
+
+// diffLines returns list of changes required to turn `linesA` into `linesB`
+func diffLines(linesA []string, linesB []string) (hunks []diffHunk) {
+	var prefix, suffix int
+
+	for prefix < len(linesA) && prefix < len(linesB) && linesA[prefix] == linesB[prefix] {
+		prefix++
+	}
+
+	for suffix < len(linesA)-prefix && suffix < len(linesB)-prefix && linesA[len(linesA)-suffix-1] == linesB[len(linesB)-suffix-1] {
+		suffix++
+	}
+
+	var (
+		midA = linesA[prefix : len(linesA)-suffix]
+		midB = linesB[prefix : len(linesB)-suffix]
+	)
+
+	if len(midA) == 0 && len(midB) == 0 {
+		return nil
+	} else if len(midA)*len(midB) > diffLinesMax {
+		return []diffHunk{{StartA: prefix, StartB: prefix, LinesA: midA, LinesB: midB}}
+	}
+
+	// Longest common subsequence of lines suffixes
+	var (
+		width = len(midB) + 1
+		lcs   = make([]int32, (len(midA)+1)*width)
+	)
+
+	for idxA := len(midA) - 1; idxA >= 0; idxA-- {
+		for idxB := len(midB) - 1; idxB >= 0; idxB-- {
+			if midA[idxA] == midB[idxB] {
+				lcs[idxA*width+idxB] = lcs[(idxA+1)*width+idxB+1] + 1
+			} else {
+				lcs[idxA*width+idxB] = max(lcs[(idxA+1)*width+idxB], lcs[idxA*width+idxB+1])
+			}
+		}
+	}
+
+	var (
+		hunk       *diffHunk
+		idxA, idxB int
+		hunkStart  = func() {
+			if hunk == nil {
+				hunk = &diffHunk{StartA: prefix + idxA, StartB: prefix + idxB}
+			}
+		}
+		hunkEnd = func() {
+			if hunk != nil {
+				hunks = append(hunks, *hunk)
+				hunk = nil
+			}
+		}
+	)
+
+	for idxA < len(midA) || idxB < len(midB) {
+		switch {
+		case idxA < len(midA) && idxB < len(midB) && midA[idxA] == midB[idxB]:
+			hunkEnd()
+			idxA++
+			idxB++
+		case idxB == len(midB) || (idxA < len(midA) && lcs[(idxA+1)*width+idxB] >= lcs[idxA*width+idxB+1]):
+			hunkStart()
+			hunk.LinesA = append(hunk.LinesA, midA[idxA])
+			idxA++
+		default:
+			hunkStart()
+			hunk.LinesB = append(hunk.LinesB, midB[idxB])
+			idxB++
+		}
+	}
+
+	hunkEnd()
+
+	return hunks
+}
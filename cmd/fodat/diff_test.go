@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/wipe2238/fo/dat"
+)
+
+func TestDiffLines(t *testing.T) {
+	var split = func(str string) []string {
+		if str == "" {
+			return nil
+		}
+
+		return strings.Split(str, " ")
+	}
+
+	for _, data := range []struct {
+		a, b     string
+		expected []diffHunk
+	}{
+		{"a b c", "a b c", nil},
+		{"", "", nil},
+		{"", "a", []diffHunk{{0, 0, nil, []string{"a"}}}},
+		{"a", "", []diffHunk{{0, 0, []string{"a"}, nil}}},
+		{"a b c", "a x c", []diffHunk{{1, 1, []string{"b"}, []string{"x"}}}},
+		{"a b c", "a c", []diffHunk{{1, 1, []string{"b"}, nil}}},
+		{"a c", "a b c", []diffHunk{{1, 1, nil, []string{"b"}}}},
+		{"a b c d e", "x b c y e", []diffHunk{{0, 0, []string{"a"}, []string{"x"}}, {3, 3, []string{"d"}, []string{"y"}}}},
+		{"a b c d", "b c d a", []diffHunk{{0, 0, []string{"a"}, nil}, {4, 3, nil, []string{"a"}}}},
+	} {
+		t.Run(data.a+"|"+data.b, func(t *testing.T) {
+			test.Eq(t, data.expected, diffLines(split(data.a), split(data.b)))
+		})
+	}
+}
+
+func TestAppDiff(t *testing.T) {
+	var fileA = WriteDat2(t, []dat.WriterFile{
+		dat.WriterFileBytes("DATA/SAME.TXT", []byte("same")),
+		dat.WriterFileBytes("DATA/DELETED.TXT", []byte("deleted")),
+		dat.WriterFileBytes("TEXT/ENGLISH/GAME/MISC.MSG", []byte("{100}{}{One}\r\n{101}{}{Two}\r\n{102}{}{Three}\r\n")),
+		dat.WriterFileBytes("ART/CRITTERS/HAPOWERA.FRM", []byte("frm")),
+	})
+
+	var fileB = WriteDat2(t, []dat.WriterFile{
+		dat.WriterFileBytes("data/same.txt", []byte("same")),
+		dat.WriterFileBytes("DATA/ADDED.TXT", []byte("added")),
+		dat.WriterFileBytes("TEXT/ENGLISH/GAME/MISC.MSG", []byte("{100}{}{One}\r\n{101}{}{2}\r\n{102}{}{Three}\r\n")),
+		dat.WriterFileBytes("ART/CRITTERS/HAPOWERA.FRM", []byte("FRM")),
+	})
+
+	var output, err = appExecOutput("diff", "--content", fileA, fileB)
+	must.NoError(t, err)
+
+	test.Eq(t, []string{
+		"M ART/CRITTERS/HAPOWERA.FRM (sha256 e9c8c418 → 7bb8698b)",
+		"A DATA/ADDED.TXT",
+		"D DATA/DELETED.TXT",
+		"M TEXT/ENGLISH/GAME/MISC.MSG (size 44 → 42, packed mode 1 → 0, sha256 72341229 → e70b6c50)",
+		"--- a/TEXT/ENGLISH/GAME/MISC.MSG",
+		"+++ b/TEXT/ENGLISH/GAME/MISC.MSG",
+		"@@ -2 +2 @@",
+		"-{101}{}{Two}",
+		"+{101}{}{2}",
+		"1 added, 1 deleted, 2 modified",
+	}, strings.Split(strings.TrimSuffix(output, "\n"), "\n"))
+
+	output, err = appExecOutput("diff", "--content=false", fileA, fileA)
+	must.NoError(t, err)
+	test.EqOp(t, "0 added, 0 deleted, 0 modified\n", output)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -11,6 +12,33 @@ import (
 
 	"github.com/wipe2238/fo/cmd"
 	"github.com/wipe2238/fo/dat"
+	"github.com/wipe2238/fo/x/dbg"
+)
+
+var optionsDump = struct {
+	Format string
+}{}
+
+// dumpDat, dumpDir and dumpFile are objects of `dump --format json` output;
+// `dbg.Map` values are written as-is, keeping their numeric/array types
+type (
+	dumpDat struct {
+		Game uint8     `json:"game"`
+		Name string    `json:"name"`
+		Dbg  dbg.Map   `json:"dbg"`
+		Dirs []dumpDir `json:"dirs"`
+	}
+
+	dumpDir struct {
+		Path  string     `json:"path"`
+		Dbg   dbg.Map    `json:"dbg"`
+		Files []dumpFile `json:"files"`
+	}
+
+	dumpFile struct {
+		Path string  `json:"path"`
+		Dbg  dbg.Map `json:"dbg"`
+	}
 )
 
 func init() {
@@ -23,6 +51,8 @@ func init() {
 		RunE:    runDump,
 	}
 
+	cmdDump.Flags().StringVar(&optionsDump.Format, "format", "text", "Output format (text, json)")
+
 	app.AddCommand(cmdDump)
 }
 
@@ -45,10 +75,19 @@ func runDump(cmdDump *cobra.Command, args []string) (err error) {
 		return err
 	}
 
-	return doDump(cmdDump, datFile, args[0])
+	switch optionsDump.Format {
+	case "text":
+		return doDump(cmdDump, datFile, args[0])
+	case "json":
+		return doDumpJSON(cmdDump, datFile, args[0])
+	}
+
+	return fmt.Errorf("dump: unknown format '%s'", optionsDump.Format)
 }
 
-func doDump(_ *cobra.Command, datFile dat.FalloutDat, datName string) (err error) {
+func doDump(cmdDump *cobra.Command, datFile dat.FalloutDat, datName string) (err error) {
+	var out = cmdDump.OutOrStdout()
+
 	var sizeHuman = func(total int64) string {
 		const unit int64 = 1024
 		if total < unit {
@@ -81,21 +120,58 @@ func doDump(_ *cobra.Command, datFile dat.FalloutDat, datName string) (err error
 			}
 		}
 
-		fmt.Println(left, "=", right)
+		fmt.Fprintln(out, left, "=", right)
 	}
 
-	fmt.Printf("DAT%d [%s]\n", datFile.GetGame(), datName)
+	fmt.Fprintf(out, "DAT%d [%s]\n", datFile.GetGame(), datName)
 	datFile.GetDbg().Dump("", "", printVal)
 
 	for _, dir := range datFile.GetDirs() {
-		fmt.Printf(" DIR [%s]\n", dir.GetPath())
+		fmt.Fprintf(out, " DIR [%s]\n", dir.GetPath())
 		dir.GetDbg().Dump("", "  ", printVal)
 
 		for _, file := range dir.GetFiles() {
-			fmt.Printf("  FILE [%s]\n", file.GetPath())
+			fmt.Fprintf(out, "  FILE [%s]\n", file.GetPath())
 			file.GetDbg().Dump("", "   ", printVal)
 		}
 	}
 
 	return nil
 }
+
+func doDumpJSON(cmdDump *cobra.Command, datFile dat.FalloutDat, datName string) (err error) {
+	var dump = dumpDat{
+		Game: datFile.GetGame(),
+		Name: datName,
+		Dbg:  dumpDbg(datFile.GetDbg()),
+		Dirs: make([]dumpDir, 0, len(datFile.GetDirs())),
+	}
+
+	for _, dir := range datFile.GetDirs() {
+		var dirDump = dumpDir{
+			Path:  dir.GetPath(),
+			Dbg:   dumpDbg(dir.GetDbg()),
+			Files: make([]dumpFile, 0, len(dir.GetFiles())),
+		}
+
+		for _, file := range dir.GetFiles() {
+			dirDump.Files = append(dirDump.Files, dumpFile{Path: file.GetPath(), Dbg: dumpDbg(file.GetDbg())})
+		}
+
+		dump.Dirs = append(dump.Dirs, dirDump)
+	}
+
+	var encoder = json.NewEncoder(cmdDump.OutOrStdout())
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(dump)
+}
+
+// dumpDbg makes sure that missing debug info is written as empty object rather than `null`
+func dumpDbg(dbgMap dbg.Map) dbg.Map {
+	if dbgMap == nil {
+		return make(dbg.Map)
+	}
+
+	return dbgMap
+}
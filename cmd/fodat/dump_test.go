@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/wipe2238/fo/dat"
+)
+
+func TestAppDump(t *testing.T) {
+	var filename = WriteDat2(t, []dat.WriterFile{
+		dat.WriterFileBytes("ART/CRITTERS/HAPOWERA.FRM", []byte("frm")),
+		dat.WriterFileBytes("ART/CRITTERS/HAPOWERB.FRM", []byte("frm")),
+		dat.WriterFileBytes("ROOT.TXT", []byte("root")),
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		var output, err = appExecOutput("dump", "--format", "text", filename)
+		must.NoError(t, err)
+		test.StrContains(t, output, "FILE [ART/CRITTERS/HAPOWERA.FRM]")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var output, err = appExecOutput("dump", "--format", "json", filename)
+		must.NoError(t, err)
+
+		var dump struct {
+			Game uint8          `json:"game"`
+			Name string         `json:"name"`
+			Dbg  map[string]any `json:"dbg"`
+			Dirs []struct {
+				Path  string         `json:"path"`
+				Dbg   map[string]any `json:"dbg"`
+				Files []struct {
+					Path string         `json:"path"`
+					Dbg  map[string]any `json:"dbg"`
+				} `json:"files"`
+			} `json:"dirs"`
+		}
+
+		must.NoError(t, json.Unmarshal([]byte(output), &dump))
+		test.EqOp(t, 2, dump.Game)
+		test.EqOp(t, filename, dump.Name)
+		test.EqOp(t, 3, dump.Dbg["DAT2:0:FilesCount"].(float64))
+		must.SliceLen(t, 2, dump.Dirs)
+
+		var filesCount int
+		for _, dir := range dump.Dirs {
+			test.MapNotEmpty(t, dir.Dbg)
+
+			for _, file := range dir.Files {
+				test.StrHasPrefix(t, dir.Path+"/", file.Path)
+				test.MapContainsKey(t, file.Dbg, "DAT2:1:Path")
+				test.MapContainsKey(t, file.Dbg, "DAT2:5:Offset")
+				filesCount++
+			}
+		}
+		test.EqOp(t, 3, filesCount)
+	})
+
+	test.Error(t, appExecMute("dump", "--format", "xml", filename))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/wipe2238/fo/dat"
+)
+
+// appExecOutput works same as `appExecLoud()`, but returns sub-command output
+func appExecOutput(args ...string) (output string, err error) {
+	var buff bytes.Buffer
+	var oldOut = app.OutOrStdout()
+
+	app.SetOut(&buff)
+	err = appExec(false, args...)
+	app.SetOut(oldOut)
+
+	return buff.String(), err
+}
+
+// WriteDat2 creates DAT2 file with given content in temporary directory
+func WriteDat2(tb testing.TB, files []dat.WriterFile) (filename string) {
+	tb.Helper()
+
+	var buff bytes.Buffer
+
+	var _, err = dat.WriteFallout2(&buff, files, dat.WriterOptions{})
+	must.NoError(tb, err)
+
+	filename = filepath.Join(tb.TempDir(), "test.dat")
+	must.NoError(tb, os.WriteFile(filename, buff.Bytes(), 0644))
+
+	return filename
+}
+
+// WriteDir creates files in temporary directory; `content` keys are slash-separated paths
+func WriteDir(tb testing.TB, content map[string]string) (dir string) {
+	tb.Helper()
+
+	dir = tb.TempDir()
+	for filePath, data := range content {
+		var filename = filepath.Join(dir, filepath.FromSlash(filePath))
+
+		must.NoError(tb, os.MkdirAll(filepath.Dir(filename), 0755))
+		must.NoError(tb, os.WriteFile(filename, []byte(data), 0644))
+	}
+
+	return dir
+}
+
+// ReadDat returns content of all files in .dat file, keys are slash-separated paths
+func ReadDat(tb testing.TB, filename string) (content map[string]string) {
+	tb.Helper()
+
+	var osFile, datFile, err = dat.Open(filename)
+	must.NoError(tb, err)
+	defer osFile.Close()
+
+	content = make(map[string]string)
+	for _, dir := range datFile.GetDirs() {
+		for _, file := range dir.GetFiles() {
+			var data []byte
+			data, err = file.GetBytesRealAt(osFile)
+			must.NoError(tb, err)
+
+			content[strings.TrimPrefix(file.GetPath(), "./")] = string(data)
+		}
+	}
+
+	return content
+}
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"path"
+	"strconv"
 
 	"github.com/spf13/cobra"
 
@@ -11,6 +17,10 @@ import (
 	"github.com/wipe2238/fo/dat"
 )
 
+var optionsList = struct {
+	Format string
+}{}
+
 func init() {
 	var cmdList = &cobra.Command{
 		Use:   "list <dat file>",
@@ -21,6 +31,8 @@ func init() {
 		RunE:    runList,
 	}
 
+	cmdList.Flags().StringVar(&optionsList.Format, "format", "text", "Output format (text, json, csv, tsv)")
+
 	app.AddCommand(cmdList)
 }
 
@@ -47,38 +59,146 @@ func runList(cmdList *cobra.Command, args []string) (err error) {
 	return doList(cmdList, datFile)
 }
 
-func doList(_ *cobra.Command, datFile dat.FalloutDat) (err error) {
+// listFile is a single row of `list` output; fields order and names must not change,
+// as output is expected to be consumed by scripts
+type listFile struct {
+	Game       uint8   `json:"game"`
+	Dir        string  `json:"dir"`
+	Name       string  `json:"name"`
+	Path       string  `json:"path"`
+	Packed     bool    `json:"packed"`
+	PackedMode uint32  `json:"packed_mode"`
+	Pack       string  `json:"pack"`
+	Offset     int64   `json:"offset"`
+	SizeReal   int64   `json:"size_real"`
+	SizePacked int64   `json:"size_packed"`
+	Ratio      float64 `json:"ratio"`
+}
+
+// listDat is a top-level object of `list --format json` output
+type listDat struct {
+	Game  uint8      `json:"game"`
+	Files []listFile `json:"files"`
+}
+
+var listHeader = []string{"game", "dir", "name", "path", "packed", "packed_mode", "pack", "offset", "size_real", "size_packed", "ratio"}
+
+func newListFile(datFile dat.FalloutDat, file dat.FalloutFile) (row listFile) {
+	row = listFile{
+		Game:       datFile.GetGame(),
+		Dir:        file.GetParentDir().GetPath(),
+		Name:       file.GetName(),
+		Path:       path.Clean(file.GetPath()),
+		Packed:     file.GetPacked(),
+		PackedMode: file.GetPackedMode(),
+		Pack:       "none",
+		Offset:     file.GetOffset(),
+		SizeReal:   file.GetSizeReal(),
+		SizePacked: file.GetSizePacked(),
+		Ratio:      1,
+	}
+
+	if file.GetPacked() {
+		switch datFile.GetGame() {
+		case 1:
+			switch file.GetPackedMode() {
+			case lzss.FalloutCompressStore:
+				row.Pack = "store"
+			case lzss.FalloutCompressLZSS:
+				row.Pack = "lzss"
+			default:
+				row.Pack = fmt.Sprintf("(%d)", file.GetPackedMode())
+			}
+		case 2:
+			row.Pack = "pack"
+		}
+	}
+
+	if file.GetSizeReal() > 0 {
+		row.Ratio = math.Round(float64(file.GetSizePacked())*10000/float64(file.GetSizeReal())) / 10000
+	}
+
+	return row
+}
+
+func (row listFile) strings() []string {
+	return []string{
+		strconv.FormatUint(uint64(row.Game), 10),
+		row.Dir,
+		row.Name,
+		row.Path,
+		strconv.FormatBool(row.Packed),
+		strconv.FormatUint(uint64(row.PackedMode), 10),
+		row.Pack,
+		strconv.FormatInt(row.Offset, 10),
+		strconv.FormatInt(row.SizeReal, 10),
+		strconv.FormatInt(row.SizePacked, 10),
+		strconv.FormatFloat(row.Ratio, 'f', 4, 64),
+	}
+}
+
+//
+
+func doList(cmdList *cobra.Command, datFile dat.FalloutDat) (err error) {
+	var out = cmdList.OutOrStdout()
+
+	switch optionsList.Format {
+	case "text":
+		return doListText(out, datFile)
+	case "json":
+		var list = listDat{Game: datFile.GetGame(), Files: make([]listFile, 0)}
+		for _, dir := range datFile.GetDirs() {
+			for _, file := range dir.GetFiles() {
+				list.Files = append(list.Files, newListFile(datFile, file))
+			}
+		}
+
+		var encoder = json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(list)
+	case "csv", "tsv":
+		var writer = csv.NewWriter(out)
+		if optionsList.Format == "tsv" {
+			writer.Comma = '\t'
+		}
+
+		if err = writer.Write(listHeader); err != nil {
+			return err
+		}
+
+		for _, dir := range datFile.GetDirs() {
+			for _, file := range dir.GetFiles() {
+				if err = writer.Write(newListFile(datFile, file).strings()); err != nil {
+					return err
+				}
+			}
+		}
+
+		writer.Flush()
+
+		return writer.Error()
+	}
+
+	return fmt.Errorf("list: unknown format '%s'", optionsList.Format)
+}
+
+func doListText(out io.Writer, datFile dat.FalloutDat) (err error) {
 	for _, dir := range datFile.GetDirs() {
-		fmt.Printf("%s\n", dir.GetPath())
+		fmt.Fprintf(out, "%s\n", dir.GetPath())
 
 		for _, file := range dir.GetFiles() {
 			var (
-				pack string = "none"
+				row  = newListFile(datFile, file)
 				save int64
 				perc uint64 = 100
 			)
 
-			if file.GetPacked() {
-				switch datFile.GetGame() {
-				case 1:
-					switch file.GetPackedMode() {
-					case lzss.FalloutCompressStore:
-						pack = "store"
-					case lzss.FalloutCompressLZSS:
-						pack = "lzss"
-					default:
-						pack = fmt.Sprintf("(%d)", file.GetPackedMode())
-					}
-				case 2:
-					pack = "pack"
-				}
-			}
-
 			if file.GetSizeReal() > 0 {
 				save = file.GetSizeReal() - file.GetSizePacked()
 				perc = (uint64(file.GetSizePacked()) * 100) / uint64(file.GetSizeReal())
 			}
-			fmt.Printf("  %-12s %5s %-10s %8d %8d %8d %3d%%\n", file.GetName(), pack, fmt.Sprintf("0x%X", file.GetOffset()), file.GetSizeReal(), file.GetSizePacked(), save, perc)
+			fmt.Fprintf(out, "  %-12s %5s %-10s %8d %8d %8d %3d%%\n", file.GetName(), row.Pack, fmt.Sprintf("0x%X", file.GetOffset()), file.GetSizeReal(), file.GetSizePacked(), save, perc)
 		}
 	}
 
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/wipe2238/fo/cmd"
+	"github.com/wipe2238/fo/dat"
+)
+
+func TestAppList(t *testing.T) {
+	var filename = WriteDat2(t, []dat.WriterFile{
+		dat.WriterFileBytes("ART/CRITTERS/HAPOWERA.FRM", bytes.Repeat([]byte("frm"), 100)),
+		dat.WriterFileBytes("ROOT.TXT", []byte("root")),
+		dat.WriterFileBytes("DATA/EMPTY.TXT", nil),
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		var output, err = appExecOutput("list", "--format", "text", filename)
+		must.NoError(t, err)
+		test.StrContains(t, output, "HAPOWERA.FRM")
+		test.StrContains(t, output, "ROOT.TXT")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var output, err = appExecOutput("list", "--format", "json", filename)
+		must.NoError(t, err)
+
+		var list listDat
+		must.NoError(t, json.Unmarshal([]byte(output), &list))
+		test.EqOp(t, 2, list.Game)
+		must.SliceLen(t, 3, list.Files)
+
+		for _, file := range list.Files {
+			test.EqOp(t, 2, file.Game)
+
+			switch file.Path {
+			case "ART/CRITTERS/HAPOWERA.FRM":
+				test.EqOp(t, "ART/CRITTERS", file.Dir)
+				test.EqOp(t, "HAPOWERA.FRM", file.Name)
+				test.True(t, file.Packed)
+				test.EqOp(t, "pack", file.Pack)
+				test.EqOp(t, 300, file.SizeReal)
+				test.Less(t, 1, file.Ratio)
+			case "DATA/EMPTY.TXT":
+				test.EqOp(t, 0, file.SizeReal)
+				test.EqOp(t, 1, file.Ratio)
+			case "ROOT.TXT":
+				test.False(t, file.Packed)
+				test.EqOp(t, "none", file.Pack)
+				test.EqOp(t, 4, file.SizePacked)
+			default:
+				t.Errorf("unexpected file: %s", file.Path)
+			}
+		}
+
+		// schema must stay the same
+		var raw struct {
+			Files []map[string]any `json:"files"`
+		}
+		must.NoError(t, json.Unmarshal([]byte(output), &raw))
+		for _, key := range listHeader {
+			test.MapContainsKey(t, raw.Files[0], key)
+		}
+	})
+
+	for format, comma := range map[string]rune{"csv": ',', "tsv": '\t'} {
+		t.Run(strings.ToUpper(format), func(t *testing.T) {
+			var output, err = appExecOutput("list", "--format", format, filename)
+			must.NoError(t, err)
+
+			var reader = csv.NewReader(strings.NewReader(output))
+			reader.Comma = comma
+
+			var records [][]string
+			records, err = reader.ReadAll()
+			must.NoError(t, err)
+			must.SliceLen(t, 4, records)
+			test.Eq(t, listHeader, records[0])
+
+			for _, record := range records[1:] {
+				test.EqOp(t, "2", record[0])
+			}
+		})
+	}
+
+	test.Error(t, appExecMute("list", "--format", "xml", filename))
+
+	t.Run("Nested", func(t *testing.T) {
+		t.Cleanup(func() { cmd.ResolveCleanup() })
+
+		var data, err = os.ReadFile(filename)
+		must.NoError(t, err)
+
+		var outer = WriteDat2(t, []dat.WriterFile{dat.WriterFileBytes("DATA/INNER.DAT", data)})
+
+		var output string
+		output, err = appExecOutput("list", "--format", "text", "@dat:"+outer+":data/inner.dat")
+		must.NoError(t, err)
+		test.StrContains(t, output, "HAPOWERA.FRM")
+		test.StrNotContains(t, output, "INNER.DAT")
+	})
+}
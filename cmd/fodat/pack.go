@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/wipe2238/fo/cmd"
+	"github.com/wipe2238/fo/dat"
+)
+
+const errPack = "pack:"
+
+var optionsPack = struct {
+	Game     uint8
+	Compress string
+	MinRatio float64
+}{}
+
+func init() {
+	var cmdPack = &cobra.Command{
+		Use:   "pack <dat file> <input directory|@fileslist>...",
+		Short: "Create DAT file",
+		Long: "Create DAT file\n\n" +
+			"Content of each input directory is added with paths relative to that directory.\n" +
+			"Names starting with '@' are text files with list of files/directories, one per line;\n" +
+			"they are added with paths relative to directory containing files list.\n\n" +
+			"Output is always the same for the same input: files are sorted, and no timestamps are stored",
+
+		GroupID: app.GroupID,
+		Args:    cobra.MinimumNArgs(2),
+		RunE:    runPack,
+	}
+
+	cmdPack.Flags().Uint8Var(&optionsPack.Game, "game", 2, "DAT file version (1, 2)")
+	cmdPack.Flags().StringVar(&optionsPack.Compress, "compress", "auto", "Compression mode (auto, always, never)")
+	cmdPack.Flags().Float64Var(&optionsPack.MinRatio, "min-ratio", 0, "Minimal compression ratio (real size / packed size) required to store compressed data, used by 'auto' compression mode")
+
+	app.AddCommand(cmdPack)
+}
+
+func runPack(cmdPack *cobra.Command, args []string) (err error) {
+	var (
+		options dat.WriterOptions
+		files   []dat.WriterFile
+	)
+
+	if options, err = packOptions(optionsPack.Game, optionsPack.Compress, optionsPack.MinRatio); err != nil {
+		return err
+	}
+
+	for _, arg := range args[1:] {
+		var filesArg []dat.WriterFile
+
+		if strings.HasPrefix(arg, "@") {
+			filesArg, err = packFilesList(filepath.Clean(arg[1:]))
+		} else {
+			filesArg, err = packFilesDir(filepath.Clean(arg), "")
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s %w", errPack, err)
+		}
+
+		files = append(files, filesArg...)
+	}
+
+	_, err = doPack(cmdPack.OutOrStdout(), filepath.Clean(args[0]), optionsPack.Game, files, options)
+
+	return err
+}
+
+// packOptions validates command line options shared by `pack` and `repack`
+func packOptions(game uint8, compress string, minRatio float64) (options dat.WriterOptions, err error) {
+	options.MinRatio = minRatio
+
+	switch compress {
+	case "auto":
+		options.Compress = dat.CompressAuto
+	case "always":
+		options.Compress = dat.CompressAlways
+	case "never":
+		options.Compress = dat.CompressNever
+	default:
+		return options, fmt.Errorf("%s invalid compression mode '%s'", errPack, compress)
+	}
+
+	if game != 1 && game != 2 {
+		return options, fmt.Errorf("%s invalid game (%d)", errPack, game)
+	}
+
+	return options, nil
+}
+
+// packFilesDir returns all files in `dir`, with paths relative to `dir` and prefixed with `prefix`
+func packFilesDir(dir string, prefix string) (files []dat.WriterFile, err error) {
+	if files, err = dat.WriterFilesFS(os.DirFS(dir)); err != nil {
+		return nil, err
+	}
+
+	for idx := range files {
+		var filename = filepath.Join(dir, filepath.FromSlash(files[idx].Path))
+
+		files[idx].Path = path.Join(prefix, files[idx].Path)
+		files[idx].Open = func() (io.ReadCloser, error) {
+			return os.Open(filename)
+		}
+	}
+
+	return files, nil
+}
+
+// packFilesList returns files and directories content listed in `filelist`,
+// with paths relative to `filelist` directory
+func packFilesList(filelist string) (files []dat.WriterFile, err error) {
+	var lines []string
+	if lines, err = cmd.ReadFileLines(filelist); err != nil {
+		return nil, err
+	}
+
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+
+		var (
+			filePath = path.Clean(strings.ReplaceAll(line, `\`, "/"))
+			filename = filepath.Join(filepath.Dir(filelist), filepath.FromSlash(filePath))
+			info     os.FileInfo
+		)
+
+		if info, err = os.Stat(filename); err != nil {
+			return nil, err
+		}
+
+		if info.IsDir() {
+			var filesDir []dat.WriterFile
+			if filesDir, err = packFilesDir(filename, filePath); err != nil {
+				return nil, err
+			}
+
+			files = append(files, filesDir...)
+		} else {
+			files = append(files, dat.WriterFile{
+				Path: filePath,
+				Open: func() (io.ReadCloser, error) {
+					return os.Open(filename)
+				},
+			})
+		}
+	}
+
+	return files, nil
+}
+
+// doPack creates .dat file; data is written to temporary file first,
+// so existing file is replaced only if whole .dat file has been created.
+// Any inputs are closed before replacing, as open files cannot be renamed over on Windows
+func doPack(out io.Writer, filename string, game uint8, files []dat.WriterFile, options dat.WriterOptions, inputs ...io.Closer) (datFile dat.FalloutDat, err error) {
+	var (
+		osFile *os.File
+		size   int64
+	)
+
+	if osFile, err = os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*"); err != nil {
+		return nil, fmt.Errorf("%s %w", errPack, err)
+	}
+
+	defer func() {
+		if err != nil {
+			osFile.Close()
+			os.Remove(osFile.Name())
+		}
+	}()
+
+	switch game {
+	case 1:
+		datFile, err = dat.WriteFallout1(osFile, files, options)
+	case 2:
+		datFile, err = dat.WriteFallout2(osFile, files, options)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if size, err = osFile.Seek(0, io.SeekCurrent); err != nil {
+		return nil, err
+	} else if err = osFile.Chmod(0644); err != nil {
+		return nil, err
+	} else if err = osFile.Close(); err != nil {
+		return nil, err
+	}
+
+	for _, input := range inputs {
+		if err = input.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = os.Rename(osFile.Name(), filename); err != nil {
+		return nil, err
+	}
+
+	var count int
+	for _, dir := range datFile.GetDirs() {
+		count += len(dir.GetFiles())
+	}
+
+	fmt.Fprintf(out, "%s: %d file(s), %d bytes\n", filename, count, size)
+
+	return datFile, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/wipe2238/fo/dat"
+)
+
+func TestAppPack(t *testing.T) {
+	var content = map[string]string{
+		"ART/CRITTERS/HAPOWERA.FRM":  strings.Repeat("frm", 1000),
+		"TEXT/ENGLISH/GAME/MISC.MSG": "{100}{}{Fallout}",
+		"DATA/EMPTY.TXT":             "",
+		"ROOT.TXT":                   "root",
+	}
+
+	var input = WriteDir(t, content)
+
+	for _, game := range []string{"1", "2"} {
+		for _, compress := range []string{"auto", "always", "never"} {
+			t.Run(fmt.Sprintf("DAT%s/%s", game, compress), func(t *testing.T) {
+				var (
+					output  = t.TempDir()
+					fileOne = filepath.Join(output, "one.dat")
+					fileTwo = filepath.Join(output, "two.dat")
+				)
+
+				must.NoError(t, appExecMute("pack", "--game", game, "--compress", compress, fileOne, input))
+				must.NoError(t, appExecMute("pack", "--game", game, "--compress", compress, fileTwo, input))
+
+				var dataOne, dataTwo []byte
+				var err error
+
+				dataOne, err = os.ReadFile(fileOne)
+				must.NoError(t, err)
+				dataTwo, err = os.ReadFile(fileTwo)
+				must.NoError(t, err)
+
+				test.True(t, bytes.Equal(dataOne, dataTwo), test.Sprint("output is not reproducible"))
+				test.Eq(t, content, ReadDat(t, fileOne))
+
+				// no temporary files left
+				var entries, _ = os.ReadDir(output)
+				test.SliceLen(t, 2, entries)
+			})
+		}
+	}
+
+	t.Run("MinRatio", func(t *testing.T) {
+		var filename = filepath.Join(t.TempDir(), "test.dat")
+
+		must.NoError(t, appExecMute("pack", "--game", "2", "--compress", "auto", "--min-ratio", "1000", filename, input))
+
+		var osFile, datFile, err = dat.Open(filename)
+		must.NoError(t, err)
+		defer osFile.Close()
+
+		for _, dir := range datFile.GetDirs() {
+			for _, file := range dir.GetFiles() {
+				test.False(t, file.GetPacked(), test.Sprint(file.GetPath()))
+			}
+		}
+	})
+
+	t.Run("Filelist", func(t *testing.T) {
+		var filelist = filepath.Join(input, "filelist.txt")
+		must.NoError(t, os.WriteFile(filelist, []byte("ART\n\nTEXT/ENGLISH/GAME/MISC.MSG\n"), 0644))
+		defer os.Remove(filelist)
+
+		var extra = WriteDir(t, map[string]string{"DATA/EXTRA.TXT": "extra"})
+		var filename = filepath.Join(t.TempDir(), "test.dat")
+
+		must.NoError(t, appExecMute("pack", "--game", "2", "--min-ratio", "0", filename, "@"+filelist, extra))
+		test.Eq(t, map[string]string{
+			"ART/CRITTERS/HAPOWERA.FRM":  content["ART/CRITTERS/HAPOWERA.FRM"],
+			"TEXT/ENGLISH/GAME/MISC.MSG": content["TEXT/ENGLISH/GAME/MISC.MSG"],
+			"DATA/EXTRA.TXT":             "extra",
+		}, ReadDat(t, filename))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		var output = t.TempDir()
+		var filename = filepath.Join(output, "test.dat")
+
+		test.Error(t, appExecMute("pack", "--game", "3", filename, input))
+		test.Error(t, appExecMute("pack", "--game", "2", "--compress", "sometimes", filename, input))
+		test.Error(t, appExecMute("pack", "--game", "2", "--compress", "auto", "--min-ratio", "-1", filename, input))
+		test.Error(t, appExecMute("pack", "--game", "2", "--min-ratio", "0", filename, filepath.Join(output, "missing")))
+		test.Error(t, appExecMute("pack", "--game", "2", filename, "@"+filepath.Join(output, "missing.txt")))
+
+		// same files added twice
+		test.Error(t, appExecMute("pack", "--game", "2", filename, input, input))
+
+		var entries, _ = os.ReadDir(output)
+		test.SliceEmpty(t, entries)
+	})
+}
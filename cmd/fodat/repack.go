@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/wipe2238/fo/cmd"
+	"github.com/wipe2238/fo/dat"
+)
+
+var optionsRepack = struct {
+	Game     uint8
+	Compress string
+	MinRatio float64
+}{}
+
+// repackStats is a single row of `repack` report
+type repackStats struct {
+	Path   string
+	Files  int
+	Real   int64 // size of unpacked content
+	Before int64 // size of packed content, before repacking
+	After  int64 // size of packed content, after repacking
+}
+
+func init() {
+	var cmdRepack = &cobra.Command{
+		Use:   "repack <dat file> <output dat file>",
+		Short: "Create new DAT file using content of existing one",
+		Long: "Create new DAT file using content of existing one\n\n" +
+			"All files are compressed again, and any unused space is dropped. If paths of two files\n" +
+			"differ by case only, first one is kept.\n" +
+			"Output file can be the same as input file; it is replaced only if repacking succeeds",
+
+		GroupID: app.GroupID,
+		Args:    cobra.ExactArgs(2),
+		RunE:    runRepack,
+	}
+
+	cmdRepack.Flags().Uint8Var(&optionsRepack.Game, "game", 0, "Output DAT file version (1, 2); if not set, same as input")
+	cmdRepack.Flags().StringVar(&optionsRepack.Compress, "compress", "auto", "Compression mode (auto, always, never)")
+	cmdRepack.Flags().Float64Var(&optionsRepack.MinRatio, "min-ratio", 0, "Minimal compression ratio (real size / packed size) required to store compressed data, used by 'auto' compression mode")
+
+	app.AddCommand(cmdRepack)
+}
+
+func runRepack(cmdRepack *cobra.Command, args []string) (err error) {
+	if err = cmd.ResolveFilename(&args[0], "@"); err != nil {
+		return err
+	}
+
+	var (
+		osFile   *os.File
+		osStat   os.FileInfo
+		datFile  dat.FalloutDat
+		datNew   dat.FalloutDat
+		game     = optionsRepack.Game
+		options  dat.WriterOptions
+		filename = filepath.Clean(args[1])
+	)
+
+	if osFile, datFile, err = dat.Open(args[0]); err != nil {
+		return err
+	}
+	defer osFile.Close()
+
+	if game == 0 {
+		game = datFile.GetGame()
+	}
+
+	if options, err = packOptions(game, optionsRepack.Compress, optionsRepack.MinRatio); err != nil {
+		return err
+	}
+
+	// Entries which differ by case only are unreachable in engine, only first one is kept
+	var files, duplicates = dat.WriterFilesDat(osFile, datFile)
+	for _, duplicate := range duplicates {
+		fmt.Fprintf(cmdRepack.ErrOrStderr(), "WARNING: skipping '%s', duplicated file path\n", duplicate)
+	}
+
+	if osStat, err = osFile.Stat(); err != nil {
+		return err
+	}
+
+	var sizeBefore = osStat.Size()
+
+	// Input is closed by doPack before output replaces it, as output might be the same file
+	if datNew, err = doPack(cmdRepack.OutOrStdout(), filename, game, files, options, osFile); err != nil {
+		return err
+	}
+
+	if osStat, err = os.Stat(filename); err != nil {
+		return err
+	}
+
+	doRepackReport(cmdRepack.OutOrStdout(), datFile, datNew, sizeBefore, osStat.Size())
+
+	return nil
+}
+
+// doRepackReport prints sizes of files content in each directory, before and after repacking
+func doRepackReport(out io.Writer, datBefore dat.FalloutDat, datAfter dat.FalloutDat, sizeBefore int64, sizeAfter int64) {
+	var stats = make(map[string]*repackStats)
+
+	// DAT1 and DAT2 might use different directories objects (or their names case), so files
+	// are grouped by their directory path
+	var each = func(datFile dat.FalloutDat, update func(*repackStats, dat.FalloutFile)) {
+		for _, dir := range datFile.GetDirs() {
+			for _, file := range dir.GetFiles() {
+				var dirPath = path.Dir(path.Clean(file.GetPath()))
+				var dirStats, ok = stats[strings.ToUpper(dirPath)]
+				if !ok {
+					dirStats = &repackStats{Path: dirPath}
+					stats[strings.ToUpper(dirPath)] = dirStats
+				}
+
+				update(dirStats, file)
+			}
+		}
+	}
+
+	each(datBefore, func(dirStats *repackStats, file dat.FalloutFile) {
+		dirStats.Files++
+		dirStats.Real += file.GetSizeReal()
+		dirStats.Before += file.GetSizePacked()
+	})
+
+	each(datAfter, func(dirStats *repackStats, file dat.FalloutFile) {
+		dirStats.After += file.GetSizePacked()
+	})
+
+	var (
+		rows  = make([]*repackStats, 0, len(stats))
+		total = repackStats{Path: "TOTAL"}
+	)
+
+	for _, dirStats := range stats {
+		rows = append(rows, dirStats)
+
+		total.Files += dirStats.Files
+		total.Real += dirStats.Real
+		total.Before += dirStats.Before
+		total.After += dirStats.After
+	}
+
+	slices.SortFunc(rows, func(a *repackStats, b *repackStats) int {
+		return strings.Compare(strings.ToLower(a.Path), strings.ToLower(b.Path))
+	})
+
+	var printRow = func(row repackStats) {
+		fmt.Fprintf(out, "%-40s %6d %12d %12d %12d %12d\n", row.Path, row.Files, row.Real, row.Before, row.After, row.Before-row.After)
+	}
+
+	fmt.Fprintf(out, "%-40s %6s %12s %12s %12s %12s\n", "DIR", "FILES", "REAL", "BEFORE", "AFTER", "SAVED")
+	for _, row := range rows {
+		printRow(*row)
+	}
+	printRow(total)
+
+	fmt.Fprintf(out, "DAT file size: %d → %d, saved %d bytes\n", sizeBefore, sizeAfter, sizeBefore-sizeAfter)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+func TestAppRepack(t *testing.T) {
+	var content = map[string]string{
+		"ART/CRITTERS/HAPOWERA.FRM":  strings.Repeat("frm", 1000),
+		"TEXT/ENGLISH/GAME/MISC.MSG": strings.Repeat("{100}{}{Fallout}\n", 100),
+		"DATA/EMPTY.TXT":             "",
+		"ROOT.TXT":                   "root",
+	}
+
+	var (
+		dir      = t.TempDir()
+		filename = filepath.Join(dir, "bloated.dat")
+	)
+
+	must.NoError(t, appExecMute("pack", "--game", "1", "--compress", "never", filename, WriteDir(t, content)))
+
+	var stat = func(filename string) int64 {
+		var info, err = os.Stat(filename)
+		must.NoError(t, err)
+
+		return info.Size()
+	}
+
+	for _, game := range []string{"0", "1", "2"} {
+		t.Run("Game"+game, func(t *testing.T) {
+			var output, err = appExecOutput("repack", "--game", game, "--compress", "auto", "--min-ratio", "0", filename, filepath.Join(dir, game+".dat"))
+			must.NoError(t, err)
+
+			test.Eq(t, content, ReadDat(t, filepath.Join(dir, game+".dat")))
+			test.Less(t, stat(filename), stat(filepath.Join(dir, game+".dat")))
+
+			test.StrContains(t, output, "ART/CRITTERS ")
+			test.StrContains(t, output, "TEXT/ENGLISH/GAME ")
+			test.StrContains(t, output, "TOTAL ")
+		})
+	}
+
+	t.Run("InPlace", func(t *testing.T) {
+		var sizeBefore = stat(filename)
+
+		must.NoError(t, appExecMute("repack", "--game", "2", filename, filename))
+		test.Eq(t, content, ReadDat(t, filename))
+		test.Less(t, sizeBefore, stat(filename))
+	})
+
+	t.Run("Duplicates", func(t *testing.T) {
+		var filename = filepath.Join(dir, "duplicates.dat")
+		must.NoError(t, appExecMute("pack", "--game", "2", filename, WriteDir(t, map[string]string{
+			"DATA/FILE1.TXT": "file1",
+			"DATA/FILE2.TXT": "file2",
+		})))
+
+		// DAT writer refuses to create such files, tree needs to be patched manually
+		var data, err = os.ReadFile(filename)
+		must.NoError(t, err)
+
+		data = bytes.Replace(data, []byte(`DATA\FILE2.TXT`), []byte(`DATA\file1.txt`), 1)
+		must.NoError(t, os.WriteFile(filename, data, 0644))
+
+		var stderr bytes.Buffer
+		app.SetErr(&stderr)
+		defer app.SetErr(os.Stderr)
+
+		must.NoError(t, appExecLoud("repack", filename, filename))
+		test.Eq(t, map[string]string{"DATA/FILE1.TXT": "file1"}, ReadDat(t, filename))
+		test.StrContains(t, stderr.String(), "DATA/file1.txt")
+	})
+
+	test.Error(t, appExecMute("repack", "--game", "3", filename, filepath.Join(dir, "invalid.dat")))
+	test.Error(t, appExecMute("repack", "--game", "2", "--compress", "sometimes", filename, filepath.Join(dir, "invalid.dat")))
+	test.Error(t, appExecMute("repack", "--game", "2", filepath.Join(dir, "missing.dat"), filepath.Join(dir, "invalid.dat")))
+	test.FileNotExists(t, filepath.Join(dir, "invalid.dat"))
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/wipe2238/fo/cmd"
+	"github.com/wipe2238/fo/dat"
+)
+
+func init() {
+	var cmdRm = &cobra.Command{
+		Use:   "rm <dat file> <file name/pattern>...",
+		Short: "Remove files from DAT2 file",
+		Long: "Remove files from DAT2 file\n\n" +
+			"Names containing '*', '?' or '[' are used as shell patterns, same as in 'unpack' command.\n" +
+			"Content of removed files is left as unused space; use 'repack' command to remove it",
+
+		GroupID: app.GroupID,
+		Args:    cobra.MinimumNArgs(2),
+		RunE:    runRm,
+	}
+
+	app.AddCommand(cmdRm)
+}
+
+func runRm(cmdRm *cobra.Command, args []string) (err error) {
+	if err = cmd.ResolveFilenameWritable(&args[0], "@"); err != nil {
+		return err
+	}
+
+	var (
+		osFile  *os.File
+		datFile dat.FalloutDat
+		remove  []string
+		missing []string
+	)
+
+	// Only list of files is needed here, DAT file is opened for writing later
+	if osFile, datFile, err = dat.Open(args[0]); err != nil {
+		return err
+	}
+	osFile.Close()
+
+	for _, name := range args[1:] {
+		var (
+			pattern = strings.ToLower(path.Clean(strings.ReplaceAll(name, `\`, "/")))
+			found   bool
+		)
+
+		for _, dir := range datFile.GetDirs() {
+			for _, file := range dir.GetFiles() {
+				var (
+					filePath = path.Clean(file.GetPath())
+					matched  = strings.ToLower(filePath) == pattern
+				)
+
+				if !matched && cmd.IsPattern(pattern) {
+					if matched, err = cmd.MatchPath(pattern, strings.ToLower(filePath)); err != nil {
+						return fmt.Errorf("%s invalid pattern '%s': %w", errUpdate, name, err)
+					}
+				}
+
+				if matched {
+					found = true
+					remove = append(remove, filePath)
+				}
+			}
+		}
+
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%s cannot find following files: '%s'", errUpdate, strings.Join(missing, "', '"))
+	}
+
+	slices.Sort(remove)
+
+	return doUpdate(cmdRm.OutOrStdout(), args[0], nil, slices.Compact(remove), dat.WriterOptions{})
+}
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -19,33 +25,34 @@ import (
 const errUnpack = "unpack:"
 
 var optionsUnpack = struct {
-	// TODO: FilenameCase
 	// TODO: Extension
+	FilenameCase  string
 	IgnoreMissing bool
-
-	Single bool
+	Regex         bool
+	Jobs          int
 }{}
 
-var doUnpackMulti func(*os.File, dat.FalloutDat, map[string]dat.FalloutFile, string) error
-
 func init() {
 	var cmdUnpack = &cobra.Command{
-		Use:   "unpack <dat file> <output directory> <file/directory name>...",
+		Use:   "unpack <dat file> <output directory> <file/directory name/pattern>...",
 		Short: "Unpack files from DAT file",
+		Long: "Unpack files from DAT file\n\n" +
+			"Names containing '*', '?' or '[' are used as shell patterns, matched against full path of each file;\n" +
+			"'**' matches zero or more directories. Matching is case-insensitive.\n\n" +
+			"Names starting with '@' are text files with list of names, one per line.\n\n" +
+			"Files which paths differ only in case can be unpacked only with '--case keep',\n" +
+			"into output directory on case-sensitive filesystem",
 
 		GroupID: app.GroupID,
 		Args:    cobra.MinimumNArgs(3),
 		RunE:    runUnpack,
 	}
 
+	cmdUnpack.Flags().StringVar(&optionsUnpack.FilenameCase, "case", "keep", "Case of unpacked directories and files names (keep, lower, upper)")
 	cmdUnpack.Flags().BoolVar(&optionsUnpack.IgnoreMissing, "ignore-missing", false, "Requested files which are not present in DAT file will be ignored")
 
-	// WIP
-	if doUnpackMulti != nil {
-		cmdUnpack.Flags().BoolVar(&optionsUnpack.Single, "single", false, "")
-	} else {
-		optionsUnpack.Single = true
-	}
+	cmdUnpack.Flags().BoolVar(&optionsUnpack.Regex, "regex", false, "Names are regular expressions, matched against full path of each file")
+	cmdUnpack.Flags().IntVarP(&optionsUnpack.Jobs, "jobs", "j", runtime.NumCPU(), "Number of files unpacked at the same time")
 
 	app.AddCommand(cmdUnpack)
 }
@@ -86,8 +93,12 @@ func runUnpack(cmdUnpack *cobra.Command, args []string) (err error) {
 		args[idx] = filepath.Clean(args[idx])
 	}
 
-	// TODO: "*"
+	// Regular expressions are used as-is, as both cleaning and lowercasing might change their meaning
 	for idx := range names {
+		if optionsUnpack.Regex {
+			continue
+		}
+
 		names[idx] = strings.ReplaceAll(names[idx], `\`, "/")
 		names[idx] = path.Clean(names[idx])
 		names[idx] = strings.ToLower(names[idx])
@@ -106,6 +117,14 @@ func runUnpack(cmdUnpack *cobra.Command, args []string) (err error) {
 func doUnpack(osFile *os.File, datFile dat.FalloutDat, names []string, dirOutput string) (err error) {
 	if len(names) < 1 {
 		return fmt.Errorf("unpack: list of files to unpack is empty")
+	} else if optionsUnpack.Jobs < 1 {
+		return fmt.Errorf("%s invalid number of jobs (%d)", errUnpack, optionsUnpack.Jobs)
+	}
+
+	switch optionsUnpack.FilenameCase {
+	case "keep", "lower", "upper":
+	default:
+		return fmt.Errorf("%s invalid filename case '%s'", errUnpack, optionsUnpack.FilenameCase)
 	}
 
 	// removes duplicates and empty entries
@@ -118,10 +137,23 @@ func doUnpack(osFile *os.File, datFile dat.FalloutDat, names []string, dirOutput
 	var durationStart = time.Now()
 	var dirMap = make(map[string]dat.FalloutDir)
 	var fileMap = make(map[string]dat.FalloutFile)
+	// Files which differ only in case, such files can be unpacked only
+	// without changing their case, into case-sensitive directory
+	var fileCollisions = make(map[string][]dat.FalloutFile)
 	for _, dir := range datFile.GetDirs() {
 		dirMap[strings.ToLower(dir.GetPath())] = dir
 		for _, file := range dir.GetFiles() {
-			fileMap[strings.ToLower(file.GetPath())] = file
+			var pathLower = strings.ToLower(file.GetPath())
+
+			if fileOther, ok := fileMap[pathLower]; ok {
+				if len(fileCollisions[pathLower]) == 0 {
+					fileCollisions[pathLower] = append(fileCollisions[pathLower], fileOther)
+				}
+
+				fileCollisions[pathLower] = append(fileCollisions[pathLower], file)
+			}
+
+			fileMap[pathLower] = file
 		}
 	}
 	var durationMaps = time.Since(durationStart)
@@ -134,6 +166,12 @@ func doUnpack(osFile *os.File, datFile dat.FalloutDat, names []string, dirOutput
 	var dirExtract = make(map[string]dat.FalloutDir)
 
 	durationStart = time.Now()
+	if err = doUnpackPatterns(fileMap, fileExtract, names); err != nil {
+		return err
+	}
+
+	names = cleanupStringSlice(names)
+
 	for idx, name := range names {
 		var pathLower string
 
@@ -187,58 +225,248 @@ func doUnpack(osFile *os.File, datFile dat.FalloutDat, names []string, dirOutput
 	}
 	var durationDirs = time.Since(durationStart)
 
+	var collisions []string
+	for pathLower := range fileExtract {
+		if files, ok := fileCollisions[pathLower]; ok {
+			var paths = make([]string, 0, len(files))
+			for _, file := range files {
+				paths = append(paths, file.GetPath())
+			}
+
+			collisions = append(collisions, "'"+strings.Join(paths, "', '")+"'")
+		}
+	}
+
+	if len(collisions) > 0 {
+		var caseSensitive bool
+		if optionsUnpack.FilenameCase == "keep" {
+			if caseSensitive, err = doUnpackCaseSensitive(dirOutput); err != nil {
+				return err
+			}
+		}
+
+		if !caseSensitive {
+			slices.Sort(collisions)
+			return fmt.Errorf("%s following files differ only in case: %s", errUnpack, strings.Join(collisions, "; "))
+		}
+	}
+
+	var sorted = make([]dat.FalloutFile, 0, len(fileExtract))
+	for pathLower, file := range fileExtract {
+		if files, ok := fileCollisions[pathLower]; ok {
+			sorted = append(sorted, files...)
+		} else {
+			sorted = append(sorted, file)
+		}
+	}
+
 	clear(fileMap)
+	clear(fileCollisions)
 	clear(dirMap)
 	clear(dirExtract)
 	runtime.GC()
 
+	slices.SortFunc(sorted, func(a dat.FalloutFile, b dat.FalloutFile) int {
+		return strings.Compare(a.GetPath(), b.GetPath())
+	})
+
 	durationStart = time.Now()
-	if optionsUnpack.Single {
-		var sorted = make([]dat.FalloutFile, 0, len(fileExtract))
-		for _, file := range fileExtract {
-			sorted = append(sorted, file)
-		}
+	if err = doUnpackMulti(osFile, sorted, dirOutput, optionsUnpack.Jobs); err != nil {
+		return err
+	}
+	var durationUnpack = time.Since(durationStart)
 
-		slices.SortFunc(sorted, func(a dat.FalloutFile, b dat.FalloutFile) int {
-			if a.GetPath() < b.GetPath() {
-				return -1
-			} else if a.GetPath() > b.GetPath() {
-				return 1
-			}
+	fmt.Println("durationMaps   =", durationMaps)
+	fmt.Println("durationFiles  =", durationFiles)
+	fmt.Println("durationDirs   =", durationDirs)
+	fmt.Println("durationUnpack =", durationUnpack)
 
-			return 0
-		})
+	return nil
+}
 
-		for _, file := range sorted {
-			var filename = filepath.Clean(filepath.FromSlash(dirOutput + "/" + file.GetPath()))
+// doUnpackPatterns adds files matching any of patterns to `fileExtract`;
+// patterns which matched at least one file are replaced with empty string
+func doUnpackPatterns(fileMap map[string]dat.FalloutFile, fileExtract map[string]dat.FalloutFile, names []string) (err error) {
+	for idx, name := range names {
+		var match func(string) (bool, error)
 
-			var bytes []byte
-			if bytes, err = file.GetBytesReal(osFile); err != nil {
-				return fmt.Errorf("unpack: %w", err)
+		if name == "" {
+			continue
+		} else if optionsUnpack.Regex {
+			var re *regexp.Regexp
+			if re, err = regexp.Compile("(?i)" + name); err != nil {
+				return fmt.Errorf("%s invalid regular expression '%s': %w", errUnpack, name, err)
 			}
 
-			if err = os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
-				return fmt.Errorf("doUnpackData(%s%c): %w", filepath.Dir(filename), filepath.Separator, err)
+			match = func(pathLower string) (bool, error) {
+				return re.MatchString(pathLower), nil
 			}
+		} else if cmd.IsPattern(name) {
+			match = func(pathLower string) (bool, error) {
+				return cmd.MatchPath(name, pathLower)
+			}
+		} else {
+			continue
+		}
 
-			if err = os.WriteFile(filename, bytes, 0644); err != nil {
-				return fmt.Errorf("doUnpackData(%s): %w", filename, err)
+		for pathLower, file := range fileMap {
+			var matched bool
+			if matched, err = match(pathLower); err != nil {
+				return fmt.Errorf("%s invalid pattern '%s': %w", errUnpack, name, err)
+			} else if matched {
+				fileExtract[pathLower] = file
+				names[idx] = ""
 			}
+		}
+	}
+
+	return nil
+}
+
+// doUnpackMulti unpacks files using up to `jobs` goroutines
+//
+// Files are written in any order, but logged in same order as they are passed;
+// first error stops all workers, files which are already unpacked are kept
+func doUnpackMulti(osFile *os.File, files []dat.FalloutFile, dirOutput string, jobs int) (err error) {
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		queue       = make(chan int)
+		results     = make([]chan error, len(files))
+		errFirst    error
+		errOnce     sync.Once
+		wait        sync.WaitGroup
+	)
+
+	defer func() {
+		cancel()
+		wait.Wait()
+	}()
+
+	for idx := range results {
+		results[idx] = make(chan error, 1)
+	}
 
-			fmt.Printf("%s → %s\n", file.GetPath(), filename)
+	// Every file is always queued, so each result channel receives exactly one value;
+	// after cancellation, workers drain the queue without unpacking anything
+	go func() {
+		defer close(queue)
+
+		for idx := range files {
+			queue <- idx
 		}
-	} else {
-		// WIP
-		if err = doUnpackMulti(osFile, datFile, fileExtract, dirOutput); err != nil {
-			return err
+	}()
+
+	for range min(jobs, len(files)) {
+		wait.Add(1)
+
+		go func() {
+			defer wait.Done()
+
+			for idx := range queue {
+				if ctx.Err() != nil {
+					results[idx] <- ctx.Err()
+					continue
+				}
+
+				var errFile = doUnpackFileTo(osFile, files[idx], dirOutput)
+				if errFile != nil {
+					errOnce.Do(func() {
+						errFirst = errFile
+						cancel()
+					})
+				}
+
+				results[idx] <- errFile
+			}
+		}()
+	}
+
+	for idx, file := range files {
+		if err = <-results[idx]; err != nil {
+			// Earlier file might be cancelled by error in later one
+			return errFirst
 		}
+
+		fmt.Printf("%s → %s\n", file.GetPath(), doUnpackFilename(file, dirOutput))
 	}
-	var durationUnpack = time.Since(durationStart)
 
-	fmt.Println("durationMaps   =", durationMaps)
-	fmt.Println("durationFiles  =", durationFiles)
-	fmt.Println("durationDirs   =", durationDirs)
-	fmt.Println("durationUnpack =", durationUnpack)
+	return nil
+}
+
+// doUnpackCaseSensitive creates output directory, and checks if it can contain files which names differ only in case
+func doUnpackCaseSensitive(dirOutput string) (caseSensitive bool, err error) {
+	var osFile *os.File
+
+	if err = os.MkdirAll(dirOutput, 0755); err != nil {
+		return false, fmt.Errorf("%s %w", errUnpack, err)
+	} else if osFile, err = os.CreateTemp(dirOutput, ".fodat-case-*"); err != nil {
+		return false, fmt.Errorf("%s %w", errUnpack, err)
+	}
+
+	osFile.Close()
+	defer os.Remove(osFile.Name())
+
+	var filename = filepath.Join(dirOutput, strings.ToUpper(filepath.Base(osFile.Name())))
+	if _, err = os.Stat(filename); errors.Is(err, fs.ErrNotExist) {
+		return true, nil
+	} else if err != nil {
+		return false, fmt.Errorf("%s %w", errUnpack, err)
+	}
+
+	return false, nil
+}
+
+// doUnpackFilename returns name of unpacked file, with case of file path changed as requested
+func doUnpackFilename(file dat.FalloutFile, dirOutput string) string {
+	var filePath = file.GetPath()
+
+	switch optionsUnpack.FilenameCase {
+	case "lower":
+		filePath = strings.ToLower(filePath)
+	case "upper":
+		filePath = strings.ToUpper(filePath)
+	}
+
+	return filepath.Clean(filepath.FromSlash(dirOutput + "/" + filePath))
+}
+
+// doUnpackFileTo creates file directory and unpacks file into it
+func doUnpackFileTo(osFile *os.File, file dat.FalloutFile, dirOutput string) (err error) {
+	var filename = doUnpackFilename(file, dirOutput)
+
+	if err = os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("doUnpackData(%s%c): %w", filepath.Dir(filename), filepath.Separator, err)
+	}
+
+	return doUnpackFile(osFile, file, filename)
+}
+
+// doUnpackFile writes file data directly to disk, without loading whole file in memory
+func doUnpackFile(osFile *os.File, file dat.FalloutFile, filename string) (err error) {
+	var (
+		reader io.ReadCloser
+		output *os.File
+	)
+
+	if reader, err = file.Open(osFile); err != nil {
+		return fmt.Errorf("%s %w", errUnpack, err)
+	}
+	defer reader.Close()
+
+	if output, err = os.Create(filename); err != nil {
+		return fmt.Errorf("doUnpackData(%s): %w", filename, err)
+	}
+
+	if _, err = io.Copy(output, reader); err != nil {
+		output.Close()
+		os.Remove(filename)
+
+		return fmt.Errorf("%s %s: %w", errUnpack, file.GetPath(), err)
+	}
+
+	if err = output.Close(); err != nil {
+		return fmt.Errorf("doUnpackData(%s): %w", filename, err)
+	}
 
 	return nil
 }
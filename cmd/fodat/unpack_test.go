@@ -1,12 +1,36 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/wipe2238/fo/dat"
 )
 
+// UnpackedFiles returns slash-separated paths of all files in given directory
+func UnpackedFiles(tb testing.TB, dir string) (files []string) {
+	tb.Helper()
+
+	must.NoError(tb, filepath.WalkDir(dir, func(filename string, dirEntry fs.DirEntry, err error) error {
+		if err == nil && !dirEntry.IsDir() {
+			filename, _ = filepath.Rel(dir, filename)
+			files = append(files, filepath.ToSlash(filename))
+		}
+
+		return err
+	}))
+
+	return files
+}
+
 func TestAppUnpack(t *testing.T) {
 	const dir = "../../bin/test.unpack"
 	const file = "ART/BACKGRND/BACKGRND.LST"
@@ -21,3 +45,153 @@ func TestAppUnpack(t *testing.T) {
 
 	os.RemoveAll(dir)
 }
+
+func TestAppUnpackJobs(t *testing.T) {
+	var files []dat.WriterFile
+	var content = make(map[string]string)
+	for idx := range 100 {
+		var filePath = fmt.Sprintf("DIR%d/FILE%03d.TXT", idx%7, idx)
+
+		content[filePath] = strings.Repeat(filePath, idx)
+		files = append(files, dat.WriterFileBytes(filePath, []byte(content[filePath])))
+	}
+
+	var filename = WriteDat2(t, files)
+
+	var args = []string{"dir0", "dir1", "dir2", "dir3", "dir4", "dir5", "dir6"}
+
+	for _, jobs := range []int{1, 4, 200} {
+		t.Run(fmt.Sprintf("Jobs%d", jobs), func(t *testing.T) {
+			var dir = t.TempDir()
+
+			must.NoError(t, appExecMute(append([]string{"unpack", "--jobs", fmt.Sprint(jobs), filename, dir}, args...)...))
+
+			for _, file := range files {
+				var data, err = os.ReadFile(filepath.Join(dir, filepath.FromSlash(file.Path)))
+				must.NoError(t, err)
+				test.EqOp(t, content[file.Path], string(data))
+			}
+		})
+	}
+
+	t.Run("Jobs0", func(t *testing.T) {
+		test.Error(t, appExecMute(append([]string{"unpack", "--jobs", "0", filename, t.TempDir()}, args...)...))
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		// directory cannot be created if file with same name already exists
+		var dir = t.TempDir()
+		must.NoError(t, os.WriteFile(filepath.Join(dir, "DIR3"), nil, 0644))
+
+		test.Error(t, appExecMute(append([]string{"unpack", "--jobs", "4", filename, dir}, args...)...))
+	})
+}
+
+func TestAppUnpackPatterns(t *testing.T) {
+	var files = []dat.WriterFile{
+		dat.WriterFileBytes("ART/CRITTERS/HAPOWERA.FRM", []byte("frm")),
+		dat.WriterFileBytes("ART/CRITTERS/HAPOWERA.FR0", []byte("fr0")),
+		dat.WriterFileBytes("SCRIPTS/OBJ_DUDE.INT", []byte("int")),
+		dat.WriterFileBytes("SCRIPTS/TEST0.INT", []byte("int")),
+		dat.WriterFileBytes("TEXT/ENGLISH/GAME/MISC.MSG", []byte("msg")),
+		dat.WriterFileBytes("TEXT/ENGLISH/DIALOG/TEST0.MSG", []byte("msg")),
+	}
+
+	var filename = WriteDat2(t, files)
+
+	var filelist = filepath.Join(t.TempDir(), "filelist.txt")
+	must.NoError(t, os.WriteFile(filelist, []byte("Scripts/Ob*.int\n**/dialog/*\n"), 0644))
+
+	for name, data := range map[string]struct {
+		args     []string
+		expected []string
+	}{
+		"Glob": {
+			[]string{"art/critters/*.frm", "**/MISC.MSG"},
+			[]string{"ART/CRITTERS/HAPOWERA.FRM", "TEXT/ENGLISH/GAME/MISC.MSG"},
+		},
+		"Filelist": {
+			[]string{"@" + filelist},
+			[]string{"SCRIPTS/OBJ_DUDE.INT", "TEXT/ENGLISH/DIALOG/TEST0.MSG"},
+		},
+		"Regex": {
+			[]string{"--regex", `test\d\.`, `\.fr0$`},
+			[]string{"ART/CRITTERS/HAPOWERA.FR0", "SCRIPTS/TEST0.INT", "TEXT/ENGLISH/DIALOG/TEST0.MSG"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var dir = t.TempDir()
+
+			must.NoError(t, appExecMute(append([]string{"unpack", "--regex=false", filename, dir}, data.args...)...))
+
+			test.Eq(t, data.expected, UnpackedFiles(t, dir))
+		})
+	}
+
+	test.Error(t, appExecMute("unpack", "--regex=false", filename, t.TempDir(), "art/*.none"))
+	test.Error(t, appExecMute("unpack", "--regex=false", filename, t.TempDir(), "art/[a-"))
+	test.Error(t, appExecMute("unpack", "--regex", filename, t.TempDir(), "art/(critters"))
+	test.NoError(t, appExecMute("unpack", "--regex=false", "--ignore-missing", filename, t.TempDir(), "art/*.none", "scripts/*"))
+}
+
+func TestAppUnpackCase(t *testing.T) {
+	var files = []dat.WriterFile{
+		dat.WriterFileBytes("Art/Critters/HaPowerA.frm", []byte("frm")),
+		dat.WriterFileBytes("DATA/FILE1.TXT", []byte("file1")),
+		dat.WriterFileBytes("DATA/FILE2.TXT", []byte("file2")),
+	}
+
+	var filename = WriteDat2(t, files)
+
+	for name, expected := range map[string][]string{
+		"keep":  {"Art/Critters/HaPowerA.frm"},
+		"lower": {"art/critters/hapowera.frm"},
+		"upper": {"ART/CRITTERS/HAPOWERA.FRM"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var dir = t.TempDir()
+
+			must.NoError(t, appExecMute("unpack", "--regex=false", "--case", name, filename, dir, "art"))
+			test.Eq(t, expected, UnpackedFiles(t, dir))
+		})
+	}
+
+	test.Error(t, appExecMute("unpack", "--case", "title", filename, t.TempDir(), "art"))
+
+	t.Run("Collision", func(t *testing.T) {
+		// DAT writer refuses to create such files, tree needs to be patched manually
+		var data, err = os.ReadFile(filename)
+		must.NoError(t, err)
+
+		data = bytes.Replace(data, []byte(`DATA\FILE2.TXT`), []byte(`DATA\file1.txt`), 1)
+		must.NoError(t, os.WriteFile(filename, data, 0644))
+
+		var dir = t.TempDir()
+		for _, name := range []string{"lower", "upper"} {
+			test.Error(t, appExecMute("unpack", "--case", name, filename, dir, "data"))
+			test.Error(t, appExecMute("unpack", "--case", name, filename, dir, "data/file1.txt"))
+		}
+
+		test.SliceEmpty(t, UnpackedFiles(t, dir))
+		test.NoError(t, appExecMute("unpack", "--case", "keep", filename, dir, "art"))
+
+		// without changing case, both files can be unpacked only if filesystem allows it
+		var caseSensitive bool
+		caseSensitive, err = doUnpackCaseSensitive(dir)
+		must.NoError(t, err)
+
+		dir = t.TempDir()
+		if caseSensitive {
+			must.NoError(t, appExecMute("unpack", "--case", "keep", filename, dir, "data/file1.txt"))
+			test.Eq(t, []string{"DATA/FILE1.TXT", "DATA/file1.txt"}, UnpackedFiles(t, dir))
+
+			var bytesFile []byte
+			bytesFile, err = os.ReadFile(filepath.Join(dir, "DATA", "file1.txt"))
+			must.NoError(t, err)
+			test.EqOp(t, "file2", string(bytesFile))
+		} else {
+			test.Error(t, appExecMute("unpack", "--case", "keep", filename, dir, "data/file1.txt"))
+			test.SliceEmpty(t, UnpackedFiles(t, dir))
+		}
+	})
+}
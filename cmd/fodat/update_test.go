@@ -0,0 +1,62 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+func TestAppAddRm(t *testing.T) {
+	var content = map[string]string{
+		"ART/CRITTERS/HAPOWERA.FRM":  strings.Repeat("frm", 1000),
+		"ART/CRITTERS/HAPOWERB.FRM":  strings.Repeat("frm", 1000),
+		"TEXT/ENGLISH/GAME/MISC.MSG": "{100}{}{Fallout}",
+		"ROOT.TXT":                   "root",
+	}
+
+	var filename = filepath.Join(t.TempDir(), "test.dat")
+
+	must.NoError(t, appExecMute("pack", "--game", "2", "--compress", "auto", "--min-ratio", "0", filename, WriteDir(t, content)))
+
+	// add new file and replace existing one
+	must.NoError(t, appExecMute("add", "--compress", "auto", filename, WriteDir(t, map[string]string{
+		"text/english/game/misc.msg": "{100}{}{Fallout 2}",
+		"DATA/ADDED.TXT":             "added",
+	})))
+
+	content["TEXT/ENGLISH/GAME/MISC.MSG"] = "{100}{}{Fallout 2}"
+	content["DATA/ADDED.TXT"] = "added"
+	test.Eq(t, content, ReadDat(t, filename))
+
+	must.NoError(t, appExecMute("rm", filename, "art/critters/*.frm", `DATA\ADDED.TXT`))
+
+	delete(content, "ART/CRITTERS/HAPOWERA.FRM")
+	delete(content, "ART/CRITTERS/HAPOWERB.FRM")
+	delete(content, "DATA/ADDED.TXT")
+	test.Eq(t, content, ReadDat(t, filename))
+
+	must.NoError(t, appExecMute("verify", filename))
+
+	test.Error(t, appExecMute("rm", filename, "DATA/MISSING.TXT"))
+	test.Error(t, appExecMute("rm", filename, "art/[a-"))
+	test.Error(t, appExecMute("add", "--compress", "sometimes", filename, t.TempDir()))
+	test.Eq(t, content, ReadDat(t, filename))
+
+	// files inside other .dat files cannot be updated, as only temporary copy would be changed
+	var outer = filepath.Join(t.TempDir(), "outer.dat")
+	must.NoError(t, appExecMute("pack", "--game", "2", "--compress", "never", outer, filepath.Dir(filename)))
+	var outerContent = ReadDat(t, outer)
+
+	test.Error(t, appExecMute("add", "--compress", "auto", "@dat:"+outer+":test.dat", WriteDir(t, map[string]string{"DATA/ADDED.TXT": "added"})))
+	test.Error(t, appExecMute("rm", "@dat:"+outer+":test.dat", "ROOT.TXT"))
+	test.Eq(t, outerContent, ReadDat(t, outer))
+
+	// DAT1 cannot be updated
+	var filenameDat1 = filepath.Join(t.TempDir(), "test.dat1")
+	must.NoError(t, appExecMute("pack", "--game", "1", filenameDat1, WriteDir(t, content)))
+	test.Error(t, appExecMute("add", "--compress", "auto", filenameDat1, WriteDir(t, map[string]string{"DATA/ADDED.TXT": "added"})))
+	test.Error(t, appExecMute("rm", filenameDat1, "ROOT.TXT"))
+}
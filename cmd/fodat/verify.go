@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/wipe2238/fo/cmd"
+	"github.com/wipe2238/fo/dat"
+)
+
+const errVerify = "verify:"
+
+func init() {
+	var cmdVerify = &cobra.Command{
+		Use:   "verify <dat file>",
+		Short: "Check integrity of DAT file",
+		Long: "Check integrity of DAT file\n\n" +
+			"Every file is unpacked and checked for size mismatch and decompression errors;\n" +
+			"data of all files is checked for exceeding data area (DAT1: after tree, DAT2: before tree),\n" +
+			"overlapping, and unused gaps. Gaps are reported, but they are not treated as errors",
+
+		GroupID: app.GroupID,
+		Args:    cobra.ExactArgs(1),
+		RunE:    runVerify,
+	}
+
+	app.AddCommand(cmdVerify)
+}
+
+func runVerify(cmdVerify *cobra.Command, args []string) (err error) {
+	if err = cmd.ResolveFilename(&args[0], "@"); err != nil {
+		return err
+	}
+
+	var (
+		osFile   *os.File
+		osStat   os.FileInfo
+		datFile  dat.FalloutDat
+		problems int
+	)
+
+	if osFile, datFile, err = dat.Open(args[0]); err != nil {
+		return err
+	}
+	defer osFile.Close()
+
+	if osStat, err = osFile.Stat(); err != nil {
+		return err
+	}
+
+	if problems, err = doVerify(cmdVerify.OutOrStdout(), osFile, osStat.Size(), datFile); err != nil {
+		return err
+	} else if problems > 0 {
+		return fmt.Errorf("%s %d problem(s) found", errVerify, problems)
+	}
+
+	return nil
+}
+
+// doVerify checks all files in .dat file and returns number of problems found
+func doVerify(out io.Writer, stream io.ReaderAt, sizeDat int64, datFile dat.FalloutDat) (problems int, err error) {
+	var files []dat.FalloutFile
+	for _, dir := range datFile.GetDirs() {
+		files = append(files, dir.GetFiles()...)
+	}
+
+	var (
+		filesCount         = len(files)
+		dataStart, dataEnd = dat.DataRange(datFile, sizeDat)
+		outside            = make(map[dat.FalloutFile]bool)
+	)
+
+	var report = func(file dat.FalloutFile, format string, args ...any) {
+		fmt.Fprintf(out, "%s: %s\n", path.Clean(file.GetPath()), fmt.Sprintf(format, args...))
+		problems++
+	}
+
+	//
+	// Content
+	//
+
+	slices.SortFunc(files, func(a dat.FalloutFile, b dat.FalloutFile) int {
+		return strings.Compare(a.GetPath(), b.GetPath())
+	})
+
+	for _, file := range files {
+		var bytesReal []byte
+
+		if file.GetOffset() < dataStart || file.GetSizePacked() < 0 || file.GetOffset()+file.GetSizePacked() > dataEnd {
+			report(file, "data range 0x%X-0x%X exceeds data area 0x%X-0x%X", file.GetOffset(), file.GetOffset()+file.GetSizePacked(), dataStart, dataEnd)
+			outside[file] = true
+			continue
+		}
+
+		if bytesReal, err = file.GetBytesRealAt(stream); err != nil {
+			report(file, "%v", err)
+			continue
+		}
+
+		if int64(len(bytesReal)) != file.GetSizeReal() {
+			report(file, "size mismatch: have(%d) != want(%d)", len(bytesReal), file.GetSizeReal())
+		}
+	}
+
+	//
+	// Data ranges
+	//
+
+	// Empty files do not use any space, they can have any offset;
+	// files outside of data area are already reported
+	files = slices.DeleteFunc(files, func(file dat.FalloutFile) bool {
+		return file.GetSizePacked() == 0 || outside[file]
+	})
+
+	slices.SortStableFunc(files, func(a dat.FalloutFile, b dat.FalloutFile) int {
+		switch {
+		case a.GetOffset() < b.GetOffset():
+			return -1
+		case a.GetOffset() > b.GetOffset():
+			return 1
+		}
+
+		return 0
+	})
+
+	var (
+		gaps     int
+		gapsSize int64
+	)
+
+	var gap = func(start int64, end int64) {
+		fmt.Fprintf(out, "gap: %d byte(s) unused at 0x%X-0x%X\n", end-start, start, end)
+
+		gaps++
+		gapsSize += end - start
+	}
+
+	// `last` is a file with data ending at highest offset so far;
+	// until first file is checked, data area start is used instead
+	var (
+		last    dat.FalloutFile
+		lastEnd = dataStart
+	)
+
+	for _, file := range files {
+		var fileEnd = file.GetOffset() + file.GetSizePacked()
+
+		if last != nil && file.GetOffset() < lastEnd {
+			report(file, "data range 0x%X-0x%X overlaps with %s", file.GetOffset(), fileEnd, path.Clean(last.GetPath()))
+		} else if file.GetOffset() > lastEnd {
+			gap(lastEnd, file.GetOffset())
+		}
+
+		if fileEnd > lastEnd {
+			last, lastEnd = file, fileEnd
+		}
+	}
+
+	if dataEnd > lastEnd {
+		gap(lastEnd, dataEnd)
+	}
+
+	fmt.Fprintf(out, "%d file(s) checked, %d problem(s), %d gap(s) (%d bytes)\n", filesCount, problems, gaps, gapsSize)
+
+	return problems, nil
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/wipe2238/fo/dat"
+)
+
+func TestAppVerify(t *testing.T) {
+	var filename = WriteDat2(t, []dat.WriterFile{
+		dat.WriterFileBytes("ART/CRITTERS/HAPOWERA.FRM", bytes.Repeat([]byte("frm"), 100)),
+		dat.WriterFileBytes("DATA/EMPTY.TXT", nil),
+		dat.WriterFileBytes("DATA/STORED.TXT", []byte("stored")),
+		dat.WriterFileBytes("TEXT/ENGLISH/GAME/MISC.MSG", bytes.Repeat([]byte("{100}{}{Fallout}\n"), 100)),
+	})
+
+	var data, err = os.ReadFile(filename)
+	must.NoError(t, err)
+
+	test.NoError(t, appExecMute("verify", filename))
+
+	// patch changes DAT2 tree entry of given file
+	type entry struct {
+		PackedMode uint8
+		SizeReal   uint32
+		SizePacked uint32
+		Offset     uint32
+	}
+
+	var patch = func(t *testing.T, filePath string, update func(*entry)) []byte {
+		var (
+			patched = bytes.Clone(data)
+			idx     = bytes.Index(patched, []byte(filePath))
+			values  entry
+			buff    bytes.Buffer
+		)
+
+		must.Positive(t, idx)
+
+		idx += len(filePath)
+		must.NoError(t, binary.Read(bytes.NewReader(patched[idx:]), binary.LittleEndian, &values))
+		update(&values)
+		must.NoError(t, binary.Write(&buff, binary.LittleEndian, values))
+		copy(patched[idx:], buff.Bytes())
+
+		return patched
+	}
+
+	var verify = func(t *testing.T, data []byte) (output string, problems int) {
+		var datFile, err = dat.Fallout2(bytes.NewReader(data))
+		must.NoError(t, err)
+
+		return verifyDat(t, data, datFile)
+	}
+
+	var datFile dat.FalloutDat
+	datFile, err = dat.Fallout2(bytes.NewReader(data))
+	must.NoError(t, err)
+
+	var _, dataEnd = dat.DataRange(datFile, int64(len(data)))
+	must.Less(t, int64(len(data)), dataEnd)
+
+	t.Run("Valid", func(t *testing.T) {
+		var output, problems = verify(t, data)
+		test.Zero(t, problems)
+		test.StrContains(t, output, "4 file(s) checked, 0 problem(s), 0 gap(s)")
+	})
+
+	t.Run("SizeReal", func(t *testing.T) {
+		var output, problems = verify(t, patch(t, `TEXT\ENGLISH\GAME\MISC.MSG`, func(values *entry) {
+			values.SizeReal++
+		}))
+		test.EqOp(t, 1, problems)
+		test.StrContains(t, output, "TEXT/ENGLISH/GAME/MISC.MSG: ")
+		test.StrContains(t, output, "size mismatch")
+	})
+
+	t.Run("SizeRealStored", func(t *testing.T) {
+		var _, problems = verify(t, patch(t, `DATA\STORED.TXT`, func(values *entry) {
+			values.SizeReal++
+		}))
+		test.EqOp(t, 1, problems)
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		var output, problems = verify(t, patch(t, `TEXT\ENGLISH\GAME\MISC.MSG`, func(values *entry) {
+			values.Offset++
+			values.SizePacked--
+		}))
+		test.EqOp(t, 1, problems)
+		test.StrContains(t, output, "TEXT/ENGLISH/GAME/MISC.MSG: zlib")
+		test.StrContains(t, output, "1 gap(s) (1 bytes)")
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		var output, problems = verify(t, patch(t, `DATA\STORED.TXT`, func(values *entry) {
+			values.Offset = uint32(len(data))
+		}))
+		test.EqOp(t, 1, problems)
+		test.StrContains(t, output, "DATA/STORED.TXT: data range")
+		test.StrContains(t, output, "exceeds data area")
+	})
+
+	t.Run("RangeTree", func(t *testing.T) {
+		var output, problems = verify(t, patch(t, `DATA\STORED.TXT`, func(values *entry) {
+			values.Offset = uint32(dataEnd)
+		}))
+		test.EqOp(t, 1, problems)
+		test.StrContains(t, output, "DATA/STORED.TXT: data range")
+		test.StrContains(t, output, "exceeds data area")
+	})
+
+	t.Run("GapFirst", func(t *testing.T) {
+		var output, _ = verify(t, patch(t, `ART\CRITTERS\HAPOWERA.FRM`, func(values *entry) {
+			values.Offset++
+			values.SizePacked--
+		}))
+		test.StrContains(t, output, "gap: 1 byte(s) unused at 0x0-0x1\n")
+		test.StrContains(t, output, "1 gap(s) (1 bytes)")
+	})
+
+	t.Run("GapLast", func(t *testing.T) {
+		var output, _ = verify(t, patch(t, `TEXT\ENGLISH\GAME\MISC.MSG`, func(values *entry) {
+			values.SizePacked--
+		}))
+		test.StrContains(t, output, fmt.Sprintf("gap: 1 byte(s) unused at 0x%X-0x%X\n", dataEnd-1, dataEnd))
+		test.StrContains(t, output, "1 gap(s) (1 bytes)")
+	})
+
+	t.Run("Overlap", func(t *testing.T) {
+		var output, problems = verify(t, patch(t, `DATA\STORED.TXT`, func(values *entry) {
+			values.Offset = 0
+		}))
+		test.Positive(t, problems)
+		test.StrContains(t, output, "overlaps with")
+	})
+
+	t.Run("App", func(t *testing.T) {
+		var corrupted = patch(t, `DATA\STORED.TXT`, func(values *entry) {
+			values.Offset = uint32(len(data))
+		})
+		must.NoError(t, os.WriteFile(filename, corrupted, 0644))
+
+		var err = appExecMute("verify", filename)
+		must.Error(t, err)
+		test.True(t, strings.HasPrefix(err.Error(), errVerify))
+	})
+}
+
+func TestAppVerifyFallout1(t *testing.T) {
+	var (
+		dir      = WriteDir(t, map[string]string{"DATA/STORED.TXT": "stored", "TEXT/MISC.MSG": "{100}{}{Fallout}"})
+		filename = filepath.Join(t.TempDir(), "test.dat")
+	)
+
+	must.NoError(t, appExecMute("pack", "--game", "1", "--compress", "never", filename, dir))
+	test.NoError(t, appExecMute("verify", filename))
+
+	var data, err = os.ReadFile(filename)
+	must.NoError(t, err)
+
+	// DAT1 tree entry: name, PackedMode, Offset, SizeReal, SizePacked
+	var idx = bytes.Index(data, []byte("STORED.TXT"))
+	must.Positive(t, idx)
+
+	t.Run("RangeTree", func(t *testing.T) {
+		var patched = bytes.Clone(data)
+		binary.BigEndian.PutUint32(patched[idx+len("STORED.TXT")+4:], 0)
+
+		var datFile, err = dat.Fallout1(bytes.NewReader(patched))
+		must.NoError(t, err)
+
+		var output, problems = verifyDat(t, patched, datFile)
+		test.EqOp(t, 1, problems)
+		test.StrContains(t, output, "DATA/STORED.TXT: data range 0x0-0x6 exceeds data area")
+	})
+
+	t.Run("GapFirst", func(t *testing.T) {
+		var patched = bytes.Clone(data)
+		var offset = binary.BigEndian.Uint32(patched[idx+len("STORED.TXT")+4:])
+		binary.BigEndian.PutUint32(patched[idx+len("STORED.TXT")+4:], offset+1)
+		binary.BigEndian.PutUint32(patched[idx+len("STORED.TXT")+12:], 5)
+
+		var datFile, err = dat.Fallout1(bytes.NewReader(patched))
+		must.NoError(t, err)
+
+		var output, _ = verifyDat(t, patched, datFile)
+		test.StrContains(t, output, fmt.Sprintf("gap: 1 byte(s) unused at 0x%X-0x%X\n", offset, offset+1))
+		test.StrContains(t, output, "1 gap(s) (1 bytes)")
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		var (
+			dir      = WriteDir(t, map[string]string{"TEXT/MISC.MSG": strings.Repeat("{100}{}{Fallout}\n", 100)})
+			filename = filepath.Join(t.TempDir(), "test.dat")
+		)
+
+		must.NoError(t, appExecMute("pack", "--game", "1", "--compress", "always", filename, dir))
+
+		var data, err = os.ReadFile(filename)
+		must.NoError(t, err)
+
+		var idx = bytes.Index(data, []byte("MISC.MSG"))
+		must.Positive(t, idx)
+
+		// LZSS data starts with size of first block, which must not be zero
+		var offset = binary.BigEndian.Uint32(data[idx+len("MISC.MSG")+4:])
+		binary.BigEndian.PutUint16(data[offset:], 0)
+
+		var datFile dat.FalloutDat
+		datFile, err = dat.Fallout1(bytes.NewReader(data))
+		must.NoError(t, err)
+
+		var output, problems = verifyDat(t, data, datFile)
+		test.EqOp(t, 1, problems)
+		test.StrContains(t, output, "TEXT/MISC.MSG: ")
+		test.StrContains(t, output, "sizeBlock == 0")
+	})
+}
+
+// verifyDat runs `verify` on .dat file data, and returns its output
+func verifyDat(t *testing.T, data []byte, datFile dat.FalloutDat) (output string, problems int) {
+	t.Helper()
+
+	var (
+		buff bytes.Buffer
+		err  error
+	)
+
+	problems, err = doVerify(&buff, bytes.NewReader(data), int64(len(data)), datFile)
+	must.NoError(t, err)
+
+	return buff.String(), problems
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"path"
+	"strings"
+)
+
+// IsPattern reports whether name contains any of special characters used by `MatchPath()`
+func IsPattern(name string) bool {
+	return strings.ContainsAny(name, `*?[`)
+}
+
+// MatchPath reports whether slash-separated name matches shell pattern.
+//
+// Pattern syntax is same as in `path.Match()`, with addition of `**` path element,
+// which matches zero or more directories. Matching is case-sensitive.
+func MatchPath(pattern string, name string) (matched bool, err error) {
+	var patterns = strings.Split(pattern, "/")
+
+	// Make sure that malformed pattern is always reported, even if name doesn't reach malformed part
+	for _, elem := range patterns {
+		if _, err = path.Match(elem, ""); err != nil {
+			return false, err
+		}
+	}
+
+	return matchPath(patterns, strings.Split(name, "/")), nil
+}
+
+func matchPath(patterns []string, names []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			for len(patterns) > 0 && patterns[0] == "**" {
+				patterns = patterns[1:]
+			}
+
+			if len(patterns) == 0 {
+				return true
+			}
+
+			for idx := range names {
+				if matchPath(patterns, names[idx:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(names) == 0 {
+			return false
+		}
+
+		if matched, _ := path.Match(patterns[0], names[0]); !matched {
+			return false
+		}
+
+		patterns, names = patterns[1:], names[1:]
+	}
+
+	return len(names) == 0
+}
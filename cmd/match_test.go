@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/shoenig/test"
+)
+
+func TestIsPattern(t *testing.T) {
+	test.True(t, IsPattern("art/critters/*.frm"))
+	test.True(t, IsPattern("scripts/ob?.int"))
+	test.True(t, IsPattern("text/[a-z]*"))
+	test.False(t, IsPattern("art/critters/hapowera.frm"))
+}
+
+func TestMatchPath(t *testing.T) {
+	for _, data := range []struct {
+		pattern string
+		name    string
+		matched bool
+	}{
+		{"art/critters/*.frm", "art/critters/hapowera.frm", true},
+		{"art/critters/*.frm", "art/critters/hapowera.fr0", false},
+		{"art/critters/*.frm", "art/critters.frm", false},
+		{"art/*", "art/critters/hapowera.frm", false},
+		{"scripts/ob*.int", "scripts/obj_dude.int", true},
+		{"scripts/ob*.int", "scripts/test.int", false},
+		{"**/*.msg", "text/english/game/misc.msg", true},
+		{"**/*.msg", "misc.msg", true},
+		{"**/*.msg", "text/english/game/misc.txt", false},
+		{"text/**/misc.msg", "text/misc.msg", true},
+		{"text/**/misc.msg", "text/english/game/misc.msg", true},
+		{"text/**/misc.msg", "data/english/game/misc.msg", false},
+		{"text/**", "text/english/game/misc.msg", true},
+		{"text/**/**/game/*", "text/english/game/misc.msg", true},
+		{"[a-c]*.lst", "ART.LST", false},
+		{"[a-c]*.lst", "art.lst", true},
+	} {
+		t.Run(data.pattern+"|"+data.name, func(t *testing.T) {
+			var matched, err = MatchPath(data.pattern, data.name)
+			test.NoError(t, err)
+			test.EqOp(t, data.matched, matched)
+		})
+	}
+}
+
+func TestMatchPathInvalid(t *testing.T) {
+	for _, pattern := range []string{"[", "art/[", "art/critters/[a-"} {
+		var _, err = MatchPath(pattern, "misc.msg")
+		test.Error(t, err)
+	}
+}
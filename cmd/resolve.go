@@ -2,16 +2,52 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 
+	"github.com/wipe2238/fo/dat"
+	"github.com/wipe2238/fo/discover"
 	"github.com/wipe2238/fo/steam"
 )
 
 var resolveMap = map[string]func(*string, string) error{
+	"env":   resolveEnv,
+	"game":  resolveGame,
+	"gog":   resolveGOG,
 	"steam": resolveSteam,
 }
 
+func init() {
+	// `resolveDat()` calls `ResolveFilename()`, which would create initialization cycle
+	resolveMap["dat"] = resolveDat
+}
+
+// resolveCopies lists resolvers which return path to temporary copy of a file
+var resolveCopies = map[string]bool{
+	"dat": true,
+}
+
 // ResolveFilename converts pseudo-filename with given prefix to absolute path.
+//
+// Supported pseudo-filenames, where <game> is one of fo1, fallout1, fo2, fallout2:
+//
+//	<prefix>steam:<game>:<filename>    Steam installation
+//	<prefix>gog:<game>:<filename>      GOG installation (Heroic, Lutris, Wine, well-known directories)
+//	<prefix>game:<game>:<filename>     any installation, Steam first
+//	<prefix>env:<variable>:<filename>  directory set by environment variable
+//	<prefix>dat:<dat filename>:<path>  file inside .dat file, see below
+//
+// <filename> must be relative to game directory; except for Steam, lookup is case-insensitive
+//
+// <dat filename> can be a regular filename or another pseudo-filename (including `dat:`), while <path>
+// is a case-insensitive path inside .dat file. File is extracted to temporary directory, and its path
+// is used as a result; `ResolveCleanup()` should be called before program exits to remove extracted files
 func ResolveFilename(filename *string, prefix string) (err error) {
 	if filename == nil {
 		return fmt.Errorf("ResolveFilename() nil filename")
@@ -41,33 +77,293 @@ func ResolveFilename(filename *string, prefix string) (err error) {
 	return fmt.Errorf("ResolveFilename() cannot resolve '%s'", *filename)
 }
 
-func resolveSteam(filename *string, prefix string) (err error) {
-	var (
-		fileparts = strings.Split(*filename, ":")
-		appID     uint64
-	)
+// ResolveFilenameWritable works same as `ResolveFilename()`, but rejects pseudo-filenames which resolve
+// to temporary copy of a file (such as `dat:`); should be used for files which are going to be modified,
+// as any changes made to a copy would be lost.
+func ResolveFilenameWritable(filename *string, prefix string) (err error) {
+	if filename != nil && len(prefix) > 0 {
+		for id := range resolveCopies {
+			if strings.HasPrefix(*filename, prefix+id) {
+				return fmt.Errorf("ResolveFilenameWritable(%s) cannot modify '%s', it resolves to temporary copy", id, *filename)
+			}
+		}
+	}
+
+	return ResolveFilename(filename, prefix)
+}
+
+// resolveTemp holds temporary directory used by `resolveDat()`
+var resolveTemp struct {
+	sync.Mutex
+	dir string
+}
+
+// ResolveCleanup removes all files extracted by `ResolveFilename()`
+func ResolveCleanup() (err error) {
+	resolveTemp.Lock()
+	defer resolveTemp.Unlock()
+
+	if resolveTemp.dir == "" {
+		return nil
+	}
+
+	err = os.RemoveAll(resolveTemp.dir)
+	resolveTemp.dir = ""
+
+	return err
+}
+
+// resolveTempDir returns new, empty directory inside temporary directory used by `resolveDat()`
+func resolveTempDir() (dir string, err error) {
+	resolveTemp.Lock()
+	defer resolveTemp.Unlock()
+
+	if resolveTemp.dir == "" {
+		if resolveTemp.dir, err = os.MkdirTemp("", "fo-resolve-"); err != nil {
+			return "", err
+		}
+	}
+
+	return os.MkdirTemp(resolveTemp.dir, "dat-")
+}
+
+// resolveParts splits pseudo-filename into `<prefix>:<value>:<filename>`
+func resolveParts(filename string, prefix string, valueName string) (value string, file string, err error) {
+	var fileparts = strings.Split(filename, ":")
 
 	if len(fileparts) != 3 {
-		return fmt.Errorf("invalid format")
+		return "", "", fmt.Errorf("invalid format")
 	} else if fileparts[0] != prefix {
-		return fmt.Errorf("invalid format (prefix)")
+		return "", "", fmt.Errorf("invalid format (prefix)")
 	} else if len(fileparts[1]) < 1 {
-		return fmt.Errorf("invalid format (game)")
+		return "", "", fmt.Errorf("invalid format (%s)", valueName)
 	} else if len(fileparts[2]) < 1 {
-		return fmt.Errorf("invalid format (filename)")
+		return "", "", fmt.Errorf("invalid format (filename)")
+	}
+
+	return fileparts[1], fileparts[2], nil
+}
+
+// resolvePartsGame works same as `resolveParts()`, and converts `<game>` value to game number
+func resolvePartsGame(filename string, prefix string) (game uint8, file string, err error) {
+	var value string
+	if value, file, err = resolveParts(filename, prefix, "game"); err != nil {
+		return 0, "", err
 	}
 
-	switch strings.ToLower(fileparts[1]) {
+	switch strings.ToLower(value) {
 	case "fo1", "fallout1":
-		appID = steam.AppID.Fallout1
+		game = 1
 	case "fo2", "fallout2":
-		appID = steam.AppID.Fallout2
+		game = 2
 	default:
-		return fmt.Errorf("invalid <game> value")
+		return 0, "", fmt.Errorf("invalid <game> value")
+	}
+
+	return game, file, nil
+}
+
+// resolveInstalls returns path to file found in first installation which has it
+func resolveInstalls(installs []discover.Install, file string) (result string, err error) {
+	if len(installs) == 0 {
+		return "", fmt.Errorf("game not found")
+	}
+
+	for _, install := range installs {
+		if result, err = resolveInstallFile(install, file); err == nil {
+			return result, nil
+		}
+	}
+
+	return "", err
+}
+
+// resolveInstallFile returns path to a regular file inside installation directory
+func resolveInstallFile(install discover.Install, file string) (result string, err error) {
+	if result, err = install.FilePath(file); err != nil {
+		return "", err
+	}
+
+	var fileInfo os.FileInfo
+	if fileInfo, err = os.Stat(result); err != nil {
+		return "", err
+	} else if !fileInfo.Mode().IsRegular() {
+		return "", fmt.Errorf("not a regular file")
+	}
+
+	return result, nil
+}
+
+// resolveSteam handles `@steam:<game>:<filename>`
+func resolveSteam(filename *string, prefix string) (err error) {
+	var (
+		game  uint8
+		file  string
+		appID uint64
+	)
+
+	if game, file, err = resolvePartsGame(*filename, prefix); err != nil {
+		return err
+	}
+
+	switch game {
+	case 1:
+		appID = steam.AppID.Fallout1
+	case 2:
+		appID = steam.AppID.Fallout2
+	}
+
+	var result string
+	if result, err = steam.GetAppFilePath(appID, file); err != nil {
+		return err
+	}
+
+	*filename = result
+
+	return nil
+}
+
+// resolveGOG handles `@gog:<game>:<filename>`, using installations found outside of Steam
+func resolveGOG(filename *string, prefix string) (err error) {
+	var (
+		game     uint8
+		file     string
+		installs []discover.Install
+	)
+
+	if game, file, err = resolvePartsGame(*filename, prefix); err != nil {
+		return err
+	}
+
+	if installs, err = discover.Find(game); err != nil {
+		return err
+	}
+
+	installs = slices.DeleteFunc(installs, func(install discover.Install) bool {
+		return install.Source == discover.SourceSteam
+	})
+
+	var result string
+	if result, err = resolveInstalls(installs, file); err != nil {
+		return err
+	}
+
+	*filename = result
+
+	return nil
+}
+
+// resolveGame handles `@game:<game>:<filename>`, using all known installations;
+// see `discover.Find()` for order in which they are checked
+func resolveGame(filename *string, prefix string) (err error) {
+	var (
+		game     uint8
+		file     string
+		installs []discover.Install
+	)
+
+	if game, file, err = resolvePartsGame(*filename, prefix); err != nil {
+		return err
+	}
+
+	if installs, err = discover.Find(game); err != nil {
+		return err
 	}
 
 	var result string
-	if result, err = steam.GetAppFilePath(appID, fileparts[2]); err != nil {
+	if result, err = resolveInstalls(installs, file); err != nil {
+		return err
+	}
+
+	*filename = result
+
+	return nil
+}
+
+// resolveEnv handles `@env:<variable>:<filename>`, where variable holds game directory
+func resolveEnv(filename *string, prefix string) (err error) {
+	var name, file string
+	if name, file, err = resolveParts(*filename, prefix, "variable"); err != nil {
+		return err
+	}
+
+	var dir = os.Getenv(name)
+	if dir == "" {
+		return fmt.Errorf("environment variable %s not set", name)
+	}
+
+	var result string
+	if result, err = resolveInstallFile(discover.Install{Path: dir}, file); err != nil {
+		return err
+	}
+
+	*filename = result
+
+	return nil
+}
+
+// resolveDat handles `@dat:<dat filename>:<path>`; see `ResolveFilename()`
+//
+// As <dat filename> might contain colons (nested pseudo-filenames, Windows drive letters),
+// <path> is always taken from last colon
+func resolveDat(filename *string, prefix string) (err error) {
+	if !strings.HasPrefix(*filename, prefix+":") {
+		return fmt.Errorf("invalid format (prefix)")
+	}
+
+	var (
+		datName = strings.TrimPrefix(*filename, prefix+":")
+		idx     = strings.LastIndex(datName, ":")
+	)
+
+	if idx < 0 {
+		return fmt.Errorf("invalid format")
+	}
+
+	var name = datName[idx+1:]
+	if datName = datName[:idx]; len(datName) < 1 {
+		return fmt.Errorf("invalid format (dat filename)")
+	} else if len(name) < 1 {
+		return fmt.Errorf("invalid format (path)")
+	}
+
+	name = strings.ReplaceAll(name, `\`, "/")
+	if !fs.ValidPath(name) || name == "." {
+		return fmt.Errorf("invalid format (path)")
+	}
+
+	// <dat filename> uses same prefix as <filename>
+	if err = ResolveFilename(&datName, strings.TrimSuffix(prefix, "dat")); err != nil {
+		return err
+	}
+
+	var (
+		osFile   *os.File
+		datFile  dat.FalloutDat
+		fileInfo fs.FileInfo
+	)
+
+	if osFile, datFile, err = dat.Open(datName); err != nil {
+		return err
+	}
+	defer osFile.Close()
+
+	if fileInfo, err = fs.Stat(dat.FS(datFile, osFile), path.Clean(name)); err != nil {
+		return err
+	}
+
+	var datEntry, ok = fileInfo.Sys().(dat.FalloutFile)
+	if !ok {
+		return fmt.Errorf("not a regular file '%s'", name)
+	}
+
+	var dir string
+	if dir, err = resolveTempDir(); err != nil {
+		return err
+	}
+
+	var result = filepath.Join(dir, fileInfo.Name())
+	if err = resolveExtract(osFile, datEntry, result); err != nil {
 		return err
 	}
 
@@ -75,3 +371,27 @@ func resolveSteam(filename *string, prefix string) (err error) {
 
 	return nil
 }
+
+// resolveExtract writes content of file inside .dat file to given filename
+func resolveExtract(stream io.ReaderAt, datEntry dat.FalloutFile, filename string) (err error) {
+	var (
+		reader io.ReadCloser
+		osFile *os.File
+	)
+
+	if reader, err = datEntry.Open(stream); err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if osFile, err = os.Create(filename); err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(osFile, reader); err != nil {
+		osFile.Close()
+		return err
+	}
+
+	return osFile.Close()
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/wipe2238/fo/steam"
+)
+
+// MakeInstalls creates fake home directory with Fallout 2 installed via Steam and GOG,
+// and Fallout 1 installed via GOG only
+func MakeInstalls(t *testing.T) (steamGame string, gogGame string, gogGame1 string) {
+	var home = t.TempDir()
+
+	t.Setenv("HOME", home)
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_DATA_HOME", "WINEPREFIX"} {
+		t.Setenv(env, "")
+	}
+
+	var steamDir = filepath.Join(home, "Steam")
+	steamGame = MakeInstall(t, filepath.Join(steamDir, "steamapps", "common", "Fallout 2"), 2)
+
+	must.NoError(t, os.WriteFile(filepath.Join(steamDir, "steamapps", "libraryfolders.vdf"),
+		[]byte(fmt.Sprintf("\"libraryfolders\"\n{\n\t\"0\"\n\t{\n\t\t\"path\"\t\t%q\n\t}\n}\n", steamDir)), 0644))
+	must.NoError(t, os.WriteFile(filepath.Join(steamDir, "steamapps", fmt.Sprintf("appmanifest_%d.acf", steam.AppID.Fallout2)),
+		[]byte("\"AppState\"\n{\n\t\"installdir\"\t\t\"Fallout 2\"\n}\n"), 0644))
+	t.Setenv(steam.EnvInstallPath, steamDir)
+
+	gogGame = MakeInstall(t, filepath.Join(home, "GOG Games", "Fallout 2"), 2, "data/gog.txt")
+	gogGame1 = MakeInstall(t, filepath.Join(home, "GOG Games", "Fallout"), 1, "Manual/readme.txt")
+
+	return steamGame, gogGame, gogGame1
+}
+
+func TestResolveStores(t *testing.T) {
+	var steamGame, gogGame, gogGame1 = MakeInstalls(t)
+
+	for filename, expected := range map[string]string{
+		"@steam:fo2:master.dat":           filepath.Join(steamGame, "master.dat"),
+		"@gog:fo2:MASTER.DAT":             filepath.Join(gogGame, "master.dat"),
+		"@gog:Fallout2:DATA/GOG.TXT":      filepath.Join(gogGame, "data", "gog.txt"),
+		`@gog:fo1:manual\README.TXT`:      filepath.Join(gogGame1, "Manual", "readme.txt"),
+		"@game:fo2:master.dat":            filepath.Join(steamGame, "master.dat"),
+		"@game:FO2:data/gog.txt":          filepath.Join(gogGame, "data", "gog.txt"),
+		"@game:fallout1:critter.dat":      filepath.Join(gogGame1, "critter.dat"),
+		"@game:fo1:Manual/../CRITTER.DAT": filepath.Join(gogGame1, "critter.dat"),
+	} {
+		t.Run(filename, func(t *testing.T) {
+			var err = ResolveFilename(&filename, "@")
+			must.NoError(t, err)
+			test.EqOp(t, expected, filename)
+		})
+	}
+
+	for _, filename := range []string{
+		"@gog:",
+		"@gog::MASTER.DAT",
+		"@gog:fo2:",
+		"@gog:fo3:MASTER.DAT",
+		"@gog:fo2:MASTER.DAT:",
+		"@gog:fo2:missing.dat",
+		"@gog:fo2:data",
+		"@gog:fo2:../Fallout/master.dat",
+		"@gog:fo2:/master.dat",
+		`@gog:fo2:\master.dat`,
+		"@steam:fo1:master.dat",
+		"@game:fo2:../../Steam/steamapps/libraryfolders.vdf",
+		"@game:fallout:master.dat",
+	} {
+		t.Run(filename, func(t *testing.T) {
+			var filenameBefore = filename
+			var err = ResolveFilename(&filename, "@")
+			test.Error(t, err)
+			test.Eq(t, filenameBefore, filename)
+		})
+	}
+
+	// without Steam, GOG installation is used
+	t.Setenv(steam.EnvInstallPath, filepath.Join(t.TempDir(), "missing"))
+
+	var filename = "@game:fo2:master.dat"
+	var err = ResolveFilename(&filename, "@")
+	must.NoError(t, err)
+	test.EqOp(t, filepath.Join(gogGame, "master.dat"), filename)
+}
@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/shoenig/test"
 	"github.com/shoenig/test/must"
 
+	"github.com/wipe2238/fo/dat"
 	"github.com/wipe2238/fo/steam"
 	"github.com/wipe2238/fo/x/maketest"
 )
@@ -210,3 +216,153 @@ func TestSteamFile(t *testing.T) {
 
 	}
 }
+
+//
+
+// MakeInstall creates fake game directory with configuration file, .dat files, and given extra files
+func MakeInstall(tb testing.TB, dir string, game uint8, files ...string) string {
+	tb.Helper()
+
+	var cfg = "fallout2.cfg"
+	if game == 1 {
+		cfg = "fallout.cfg"
+	}
+
+	for _, file := range append([]string{cfg, "master.dat", "critter.dat"}, files...) {
+		var filename = filepath.Join(dir, filepath.FromSlash(file))
+
+		must.NoError(tb, os.MkdirAll(filepath.Dir(filename), 0755))
+		must.NoError(tb, os.WriteFile(filename, []byte(file), 0644))
+	}
+
+	return dir
+}
+
+func TestResolveEnv(t *testing.T) {
+	var gameDir = MakeInstall(t, t.TempDir(), 2, "data/Maps/artemple.map")
+	t.Setenv("FO_TEST_GAME", gameDir)
+	t.Setenv("FO_TEST_EMPTY", "")
+
+	for filename, expected := range map[string]string{
+		"@env:FO_TEST_GAME:MASTER.DAT":             filepath.Join(gameDir, "master.dat"),
+		`@env:FO_TEST_GAME:DATA\MAPS\ARTEMPLE.MAP`: filepath.Join(gameDir, "data", "Maps", "artemple.map"),
+	} {
+		t.Run(filename, func(t *testing.T) {
+			var err = ResolveFilename(&filename, "@")
+			must.NoError(t, err)
+			test.EqOp(t, expected, filename)
+		})
+	}
+
+	for _, filename := range []string{
+		"@env:",
+		"@env:FO_TEST_GAME",
+		"@env::MASTER.DAT",
+		"@env:FO_TEST_GAME:",
+		"@env:FO_TEST_EMPTY:MASTER.DAT",
+		"@env:FO_TEST_MISSING:MASTER.DAT",
+		"@env:FO_TEST_GAME:missing.dat",
+		"@env:FO_TEST_GAME:data",
+		"@env:FO_TEST_GAME:./master.dat/..",
+		"@env:FO_TEST_GAME:../master.dat",
+		"@env:FO_TEST_GAME:/etc/passwd",
+	} {
+		t.Run(filename, func(t *testing.T) {
+			var filenameBefore = filename
+			var err = ResolveFilename(&filename, "@")
+			test.Error(t, err)
+			test.Eq(t, filenameBefore, filename)
+		})
+	}
+}
+
+func TestResolveDat(t *testing.T) {
+	t.Cleanup(func() { ResolveCleanup() })
+
+	var (
+		err   error
+		inner bytes.Buffer
+	)
+
+	_, err = dat.WriteFallout2(&inner, []dat.WriterFile{
+		dat.WriterFileBytes("TEXT/ENGLISH/GAME/MISC.MSG", []byte("{100}{}{Nested}")),
+	}, dat.WriterOptions{})
+	must.NoError(t, err)
+
+	var gameDir = t.TempDir()
+	t.Setenv("FO_TEST_GAME", gameDir)
+
+	var osFile *os.File
+	osFile, err = os.Create(filepath.Join(gameDir, "master.dat"))
+	must.NoError(t, err)
+
+	_, err = dat.WriteFallout2(osFile, []dat.WriterFile{
+		dat.WriterFileBytes("ART/INTRFACE/IFACE.FRM", bytes.Repeat([]byte("iface"), 100)),
+		dat.WriterFileBytes("DATA/INNER.DAT", inner.Bytes()),
+		dat.WriterFileBytes("ROOT.TXT", []byte("root")),
+	}, dat.WriterOptions{})
+	must.NoError(t, err)
+	must.NoError(t, osFile.Close())
+
+	var masterDat = filepath.Join(gameDir, "master.dat")
+
+	for filename, expected := range map[string]string{
+		"@dat:" + masterDat + ":art/intrface/iface.frm":                                    strings.Repeat("iface", 100),
+		"@dat:" + masterDat + `:ART\INTRFACE\IFACE.FRM`:                                    strings.Repeat("iface", 100),
+		"@dat:" + masterDat + ":root.txt":                                                  "root",
+		"@dat:@env:FO_TEST_GAME:MASTER.DAT:Root.txt":                                       "root",
+		"@dat:@dat:@env:FO_TEST_GAME:master.dat:data/inner.dat:text/english/game/misc.msg": "{100}{}{Nested}",
+	} {
+		t.Run(filename, func(t *testing.T) {
+			var ext = strings.ToLower(path.Ext(filename))
+			var err = ResolveFilename(&filename, "@")
+			must.NoError(t, err)
+			test.FileExists(t, filename)
+			test.EqOp(t, ext, strings.ToLower(filepath.Ext(filename)))
+
+			var data []byte
+			data, err = os.ReadFile(filename)
+			must.NoError(t, err)
+			test.EqOp(t, expected, string(data))
+		})
+	}
+
+	for _, filename := range []string{
+		"@dat:",
+		"@dat:" + masterDat,
+		"@dat:" + masterDat + ":",
+		"@dat::root.txt",
+		"@dat:" + masterDat + ":missing.txt",
+		"@dat:" + masterDat + ":art/intrface",
+		"@dat:" + masterDat + ":../root.txt",
+		"@dat:" + masterDat + ":/root.txt",
+		"@dat:" + filepath.Join(gameDir, "missing.dat") + ":root.txt",
+		"@dat:@env:FO_TEST_MISSING:master.dat:root.txt",
+		"@dat:@dat:" + masterDat + ":root.txt:root.txt",
+	} {
+		t.Run(filename, func(t *testing.T) {
+			var filenameBefore = filename
+			var err = ResolveFilename(&filename, "@")
+			test.Error(t, err)
+			test.Eq(t, filenameBefore, filename)
+		})
+	}
+
+	// extracted files are removed
+	var filename = "@dat:" + masterDat + ":root.txt"
+	must.NoError(t, ResolveFilename(&filename, "@"))
+	test.FileExists(t, filename)
+
+	must.NoError(t, ResolveCleanup())
+	test.FileNotExists(t, filename)
+	// temporary copies cannot be modified
+	for _, filename := range []string{"@dat:" + masterDat + ":root.txt", "@dat:@env:FO_TEST_GAME:master.dat:root.txt"} {
+		var filenameBefore = filename
+		test.Error(t, ResolveFilenameWritable(&filename, "@"))
+		test.Eq(t, filenameBefore, filename)
+	}
+
+	filename = "@env:FO_TEST_GAME:master.dat"
+	must.NoError(t, ResolveFilenameWritable(&filename, "@"))
+	test.EqOp(t, masterDat, filename)
+}
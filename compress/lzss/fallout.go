@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"slices"
 )
 
 const (
@@ -13,6 +14,13 @@ const (
 	FalloutCompressLZSS  uint32 = 0x40
 )
 
+// FalloutBlockSize is a maximum size of uncompressed data in a single block created by `FalloutFile.Compress()`
+const FalloutBlockSize = 0x4000
+
+// LZSS configuration is hidden from user for simplicity; there's hardly any reason
+// to change it, except intentionally breaking whole point of `FalloutFile`
+var falloutLZSS = LZSS{DictionarySize: 4096, MinMatch: 3, MaxMatch: 18}
+
 // FalloutFile is a small wrapper around LZSS holding minimal required data about DAT1 file entry
 type FalloutFile struct {
 	// Stream containing compressed file data
@@ -69,7 +77,7 @@ func (file FalloutFile) Decompress() (bytes []byte, err error) {
 		// Simply copy all bytes as-is, without involving LZSS in the process
 
 		bytes = make([]byte, file.SizePacked)
-		if _, err = file.Stream.Read(bytes); err != nil {
+		if _, err = io.ReadFull(file.Stream, bytes); err != nil {
 			return nil, fmt.Errorf("%s cannot read uncompressed file: %w", errPackage, err)
 		}
 
@@ -96,25 +104,18 @@ func (file FalloutFile) Decompress() (bytes []byte, err error) {
 				// Simply copy all bytes as-is, without involving LZSS in the process
 
 				bytesBlock = make([]byte, -sizeBlock)
-				if _, err = file.Stream.Read(bytesBlock); err != nil {
+				if _, err = io.ReadFull(file.Stream, bytesBlock); err != nil {
 					return nil, err
 				}
 			} else if sizeBlock > 0 {
 				// Compressed block
-				// LZSS configuration is hidden from user for simplicity; there's hardly any reason
-				// to change it, except intentionally breaking whole point of parent struct
-
-				var dat1 = LZSS{DictionarySize: 4096, MinMatch: 3, MaxMatch: 18}
 
-				if bytesBlock, err = dat1.Decompress(file.Stream, sizeBlock); err != nil {
+				if bytesBlock, err = falloutLZSS.Decompress(file.Stream, sizeBlock); err != nil {
 					return nil, err
 				}
 			} else {
 				// None of MASTER.DAT / CRITTER.DAT / FALLDEMO.DAT contains file with sizeBlock=0
-				if true {
-					panic("sizeBlock == 0")
-				}
-				break
+				return nil, fmt.Errorf("%s sizeBlock == 0", errPackage)
 			}
 
 			bytes = append(bytes, bytesBlock...)
@@ -132,6 +133,90 @@ func (file FalloutFile) Decompress() (bytes []byte, err error) {
 	return bytes, nil
 }
 
+// Compress returns data in format expected by `Decompress()`
+//
+// With `FalloutCompressLZSS`, data is split into blocks containing up to `FalloutBlockSize` bytes;
+// each block starts with int16 describing its size. Blocks which cannot be compressed are stored as-is,
+// with negative size.
+//
+// Note that this function ignores all fields except `CompressMode`
+func (file FalloutFile) Compress(bytes []byte) (bytesPacked []byte, err error) {
+	switch file.CompressMode {
+	case FalloutCompressStore:
+		return nil, fmt.Errorf("%s FalloutCompressStore", errPackage)
+
+	case FalloutCompressNone:
+		return slices.Clone(bytes), nil
+
+	case FalloutCompressLZSS:
+		if len(bytes) == 0 {
+			// Decompress() would refuse to handle data without any blocks
+			return nil, fmt.Errorf("%s cannot compress empty data", errPackage)
+		}
+
+		bytesPacked = make([]byte, 0, len(bytes))
+
+		for begin := 0; begin < len(bytes); begin += FalloutBlockSize {
+			var (
+				bytesBlock = bytes[begin:min((begin+FalloutBlockSize), len(bytes))]
+				bytesLZSS  []byte
+				sizeBlock  int16
+			)
+
+			if bytesLZSS, err = falloutLZSS.Compress(bytesBlock); err != nil {
+				return nil, err
+			}
+
+			if len(bytesLZSS) < len(bytesBlock) {
+				// Compressed block
+				sizeBlock = int16(len(bytesLZSS))
+				bytesBlock = bytesLZSS
+			} else {
+				// Uncompressed block
+				sizeBlock = -int16(len(bytesBlock))
+			}
+
+			bytesPacked = binary.BigEndian.AppendUint16(bytesPacked, uint16(sizeBlock))
+			bytesPacked = append(bytesPacked, bytesBlock...)
+		}
+
+		return bytesPacked, nil
+	}
+
+	return nil, fmt.Errorf("%s unknown compress mode 0x%X = %d", errPackage, file.CompressMode, file.CompressMode)
+}
+
+// DecompressAt works same as `Decompress()`, but uses given stream instead of `Stream`
+//
+// As `stream` position is never changed, it's safe to use same stream from multiple goroutines
+func (file FalloutFile) DecompressAt(stream io.ReaderAt) (bytes []byte, err error) {
+	var reader io.Reader
+
+	if reader, err = NewFalloutReader(io.NewSectionReader(stream, file.Offset, file.SizePacked), file.SizePacked, file.CompressMode); err != nil {
+		return nil, err
+	}
+
+	if bytes, err = io.ReadAll(reader); err != nil {
+		return nil, err
+	}
+
+	if file.CompressMode == FalloutCompressNone && int64(len(bytes)) != file.SizePacked {
+		return nil, fmt.Errorf("%s cannot read uncompressed file: %w", errPackage, io.ErrUnexpectedEOF)
+	}
+
+	return bytes, nil
+}
+
+// ReadBlocksSizeAt works same as `ReadBlocksSize()`, but uses given stream instead of `Stream`,
+// and reads blocks starting from `Offset` rather than current stream position
+func (file FalloutFile) ReadBlocksSizeAt(stream io.ReaderAt) (blocks []int64, err error) {
+	var fileAt = file
+
+	fileAt.Stream = io.NewSectionReader(stream, file.Offset, file.SizePacked)
+
+	return fileAt.ReadBlocksSize()
+}
+
 // ReadBlocks returns compressed file split into block defined by DAT1
 func (file FalloutFile) ReadBlocks() (blocksBytes [][]byte, err error) {
 	// Note that this function is mostly for convenience, when user wants detailed info about files
@@ -163,7 +248,7 @@ func (file FalloutFile) ReadBlocks() (blocksBytes [][]byte, err error) {
 
 		blocksBytes[idx] = make([]byte, blockSize)
 
-		if _, err = file.Stream.Read(blocksBytes[idx]); err != nil {
+		if _, err = io.ReadFull(file.Stream, blocksBytes[idx]); err != nil {
 			return nil, err
 		}
 	}
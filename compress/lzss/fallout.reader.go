@@ -0,0 +1,86 @@
+package lzss
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// falloutReader decompresses DAT1 file data block by block
+type falloutReader struct {
+	reader     io.Reader
+	sizePacked int64
+
+	block []byte // decompressed data which hasn't been read yet
+}
+
+// NewFalloutReader returns reader decompressing data on the fly, one block at a time
+//
+// `reader` must be set to file data start, and must not be used by anything else until all
+// data has been read. `sizePacked` and `compressMode` have the same meaning as in `FalloutFile`
+func NewFalloutReader(reader io.Reader, sizePacked int64, compressMode uint32) (io.Reader, error) {
+	switch compressMode {
+	case FalloutCompressStore:
+		return nil, fmt.Errorf("%s FalloutCompressStore", errPackage)
+	case FalloutCompressNone:
+		return io.LimitReader(reader, sizePacked), nil
+	case FalloutCompressLZSS:
+		return &falloutReader{reader: reader, sizePacked: sizePacked}, nil
+	}
+
+	return nil, fmt.Errorf("%s unknown compress mode 0x%X = %d", errPackage, compressMode, compressMode)
+}
+
+// Read implements `io.Reader`
+func (reader *falloutReader) Read(buff []byte) (size int, err error) {
+	for len(reader.block) == 0 {
+		if reader.sizePacked <= 0 {
+			return 0, io.EOF
+		}
+
+		if err = reader.readBlock(); err != nil {
+			return 0, err
+		}
+	}
+
+	size = copy(buff, reader.block)
+	reader.block = reader.block[size:]
+
+	return size, nil
+}
+
+// readBlock decompresses next block; see `FalloutFile.ReadBlocksSize()` for details
+func (reader *falloutReader) readBlock() (err error) {
+	var sizeBlock int16
+
+	if err = binary.Read(reader.reader, binary.BigEndian, &sizeBlock); err != nil {
+		return fmt.Errorf("%s cannot read block size: %w", errPackage, err)
+	}
+
+	reader.sizePacked -= 2 /* sizeof(int16) */
+
+	if sizeBlock == 0 {
+		return fmt.Errorf("%s sizeBlock == 0", errPackage)
+	}
+
+	// Last block's size must be clamped, see `FalloutFile.ReadBlocksSize()`
+	var sizeBlockReal = min(int64(math.Abs(float64(sizeBlock))), reader.sizePacked)
+
+	var bytesBlock = make([]byte, sizeBlockReal)
+	if _, err = io.ReadFull(reader.reader, bytesBlock); err != nil {
+		return fmt.Errorf("%s cannot read block: %w", errPackage, err)
+	}
+
+	reader.sizePacked -= sizeBlockReal
+
+	if sizeBlock < 0 {
+		// Uncompressed block
+		reader.block = bytesBlock
+	} else if reader.block, err = falloutLZSS.Decompress(bytes.NewReader(bytesBlock), sizeBlockReal); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package lzss
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+func TestFalloutCompress(t *testing.T) {
+	DoData(t, func(t *testing.T, bytesReal []byte) {
+		for _, mode := range []uint32{FalloutCompressNone, FalloutCompressLZSS} {
+			var (
+				err           error
+				file          = FalloutFile{CompressMode: mode}
+				bytesPacked   []byte
+				bytesUnpacked []byte
+				blocks        []int64
+			)
+
+			bytesPacked, err = file.Compress(bytesReal)
+			must.NoError(t, err)
+
+			// extra byte makes sure Decompress() doesn't read too much
+			file.Stream = bytes.NewReader(append(bytesPacked, 0xFF))
+			file.SizePacked = int64(len(bytesPacked))
+
+			bytesUnpacked, err = file.Decompress()
+			must.NoError(t, err)
+			test.SliceEqFunc(t, bytesReal, bytesUnpacked, func(a, b byte) bool { return a == b })
+
+			// offset makes sure DecompressAt() uses `Offset`
+			var fileAt = FalloutFile{Offset: 3, SizePacked: file.SizePacked, CompressMode: mode}
+			bytesUnpacked, err = fileAt.DecompressAt(bytes.NewReader(append([]byte{1, 2, 3}, bytesPacked...)))
+			must.NoError(t, err)
+			test.SliceEqFunc(t, bytesReal, bytesUnpacked, func(a, b byte) bool { return a == b })
+
+			var reader io.Reader
+			reader, err = NewFalloutReader(bytes.NewReader(bytesPacked), file.SizePacked, mode)
+			must.NoError(t, err)
+
+			bytesUnpacked, err = io.ReadAll(reader)
+			must.NoError(t, err)
+			test.SliceEqFunc(t, bytesReal, bytesUnpacked, func(a, b byte) bool { return a == b })
+
+			if mode != FalloutCompressLZSS {
+				continue
+			}
+
+			blocks, err = file.ReadBlocksSize()
+			must.NoError(t, err)
+			test.SliceLen(t, ((len(bytesReal) + FalloutBlockSize - 1) / FalloutBlockSize), blocks)
+
+			var blocksAt []int64
+			blocksAt, err = fileAt.ReadBlocksSizeAt(bytes.NewReader(append([]byte{1, 2, 3}, bytesPacked...)))
+			must.NoError(t, err)
+			test.Eq(t, blocks, blocksAt)
+
+			for _, sizeBlock := range blocks {
+				test.NotEq(t, 0, sizeBlock)
+				test.LessEq(t, FalloutBlockSize, max(sizeBlock, -sizeBlock))
+			}
+		}
+	})
+}
+
+func TestFalloutCompressBlocks(t *testing.T) {
+	var (
+		bytesReal = make([]byte, (FalloutBlockSize * 2))
+		file      = FalloutFile{CompressMode: FalloutCompressLZSS}
+	)
+
+	// First block can be compressed, second cannot
+	rand.New(rand.NewSource(2238)).Read(bytesReal[FalloutBlockSize:])
+
+	var bytesPacked, err = file.Compress(bytesReal)
+	must.NoError(t, err)
+
+	file.Stream = bytes.NewReader(bytesPacked)
+	file.SizePacked = int64(len(bytesPacked))
+
+	var blocks []int64
+	blocks, err = file.ReadBlocksSize()
+	must.NoError(t, err)
+	must.SliceLen(t, 2, blocks)
+	test.Positive(t, blocks[0])
+	test.EqOp(t, -FalloutBlockSize, blocks[1])
+}
+
+func TestFalloutCompressInvalid(t *testing.T) {
+	for _, file := range []FalloutFile{
+		{CompressMode: FalloutCompressStore},
+		{CompressMode: 0},
+	} {
+		var _, err = file.Compress([]byte("Fallout"))
+		test.Error(t, err)
+	}
+
+	var _, err = FalloutFile{CompressMode: FalloutCompressLZSS}.Compress([]byte{})
+	test.Error(t, err)
+}
+
+func TestFalloutCompressExtracted(t *testing.T) {
+	var dir = filepath.Join("..", "..", "dat", "testdata", "extracted", "fallout1")
+
+	var filesPacked []string
+	var err = filepath.WalkDir(dir, func(filename string, dirEntry fs.DirEntry, err error) error {
+		if err == nil && dirEntry.Name() == "Packed" {
+			filesPacked = append(filesPacked, filename)
+		}
+
+		return err
+	})
+	must.NoError(t, err)
+	must.SliceNotEmpty(t, filesPacked)
+
+	for _, filePacked := range filesPacked {
+		var name, _ = filepath.Rel(dir, filepath.Dir(filePacked))
+
+		t.Run(filepath.ToSlash(name), func(t *testing.T) {
+			var bytesReal, bytesPacked, bytesCompressed []byte
+
+			bytesReal, err = os.ReadFile(filepath.Join(filepath.Dir(filePacked), "Real"))
+			must.NoError(t, err)
+
+			bytesPacked, err = os.ReadFile(filePacked)
+			must.NoError(t, err)
+
+			bytesCompressed, err = FalloutFile{CompressMode: FalloutCompressLZSS}.Compress(bytesReal)
+			must.NoError(t, err)
+
+			// Output is not identical to original files, but should be very close in size
+			test.LessEq(t, (len(bytesPacked) + len(bytesPacked)/100), len(bytesCompressed))
+		})
+	}
+}
+
+func TestFalloutDecompressInvalid(t *testing.T) {
+	for _, data := range [][]byte{
+		{0x00},             // truncated block size
+		{0x00, 0x00, 0x01}, // sizeBlock == 0
+	} {
+		var file = FalloutFile{Stream: bytes.NewReader(data), SizePacked: int64(len(data)), CompressMode: FalloutCompressLZSS}
+
+		var _, err = file.Decompress()
+		test.Error(t, err)
+	}
+}
+
+func TestFalloutReaderInvalid(t *testing.T) {
+	var err error
+
+	for _, mode := range []uint32{FalloutCompressStore, 0} {
+		_, err = NewFalloutReader(bytes.NewReader([]byte{}), 0, mode)
+		test.Error(t, err)
+	}
+
+	for _, data := range [][]byte{
+		{0x00},             // truncated block size
+		{0x00, 0x00, 0x01}, // sizeBlock == 0
+		{0x00, 0x08, 0x01}, // truncated block
+	} {
+		var reader io.Reader
+		reader, err = NewFalloutReader(bytes.NewReader(data), 10, FalloutCompressLZSS)
+		must.NoError(t, err)
+
+		_, err = io.ReadAll(reader)
+		test.Error(t, err)
+	}
+}
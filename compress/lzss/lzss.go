@@ -21,17 +21,25 @@ var Default = LZSS{
 
 const errPackage = "fo/compress/lzss:"
 
-// Decompress
-func (lzss LZSS) Decompress(reader io.Reader, size int64) (output []byte, err error) {
-	// sanity check
+func (lzss LZSS) validate() error {
 	if lzss.DictionarySize == 0 {
-		return nil, fmt.Errorf("%s DictionarySize == 0", errPackage)
+		return fmt.Errorf("%s DictionarySize == 0", errPackage)
 	} else if lzss.MinMatch == 0 {
-		return nil, fmt.Errorf("%s MinMatch == 0", errPackage)
+		return fmt.Errorf("%s MinMatch == 0", errPackage)
 	} else if lzss.MaxMatch == 0 {
-		return nil, fmt.Errorf("%s MaxMatch == 0", errPackage)
+		return fmt.Errorf("%s MaxMatch == 0", errPackage)
 	} else if (lzss.DictionarySize % 2) != 0 {
-		return nil, fmt.Errorf("%s DictionarySize %% 2 != 0", errPackage)
+		return fmt.Errorf("%s DictionarySize %% 2 != 0", errPackage)
+	}
+
+	return nil
+}
+
+// Decompress
+func (lzss LZSS) Decompress(reader io.Reader, size int64) (output []byte, err error) {
+	// sanity check
+	if err = lzss.validate(); err != nil {
+		return nil, err
 	} else if size < 0 {
 		return nil, fmt.Errorf("%s size(%d) < 0", errPackage, size)
 	}
@@ -138,3 +146,143 @@ func (lzss LZSS) Decompress(reader io.Reader, size int64) (output []byte, err er
 
 	return output, nil
 }
+
+// Compress returns compressed data, which can be restored with `Decompress()`
+//
+// Dictionary is filled with spaces before compression starts, same as in `Decompress()`;
+// matches are searched within last `DictionarySize - MaxMatch` bytes
+func (lzss LZSS) Compress(input []byte) (output []byte, err error) {
+	// sanity check
+	if err = lzss.validate(); err != nil {
+		return nil, err
+	} else if lzss.DictionarySize > 4096 {
+		// dictionary offset is saved using 12 bits
+		return nil, fmt.Errorf("%s DictionarySize(%d) > 4096", errPackage, lzss.DictionarySize)
+	} else if lzss.MinMatch > lzss.MaxMatch {
+		return nil, fmt.Errorf("%s MinMatch(%d) > MaxMatch(%d)", errPackage, lzss.MinMatch, lzss.MaxMatch)
+	} else if uint16(lzss.MaxMatch) >= lzss.DictionarySize {
+		return nil, fmt.Errorf("%s MaxMatch(%d) >= DictionarySize(%d)", errPackage, lzss.MaxMatch, lzss.DictionarySize)
+	}
+
+	const (
+		hashBits  = 15
+		hashChain = 512 // limits number of checked matches per byte, trading size for speed
+	)
+
+	var (
+		dictionarySize = int(lzss.DictionarySize)
+		minMatch       = int(lzss.MinMatch)
+		maxMatch       = min(int(lzss.MaxMatch), (minMatch + 0x0F)) // match length is saved using 4 bits
+
+		// Initial dictionary content is treated as if it would be a part of input,
+		// placed right before actual data; that way `buff` index can be easily converted
+		// to dictionary offset used by `Decompress()`
+		window = dictionarySize - int(lzss.MaxMatch)
+		buff   = make([]byte, window+len(input))
+
+		hashLen  = min(minMatch, 3)
+		hashHead = make([]int32, (1 << hashBits))
+		hashPrev = make([]int32, len(buff))
+
+		flagsIdx int
+		flagsBit = 8
+	)
+
+	for idx := range window {
+		buff[idx] = ' '
+	}
+	copy(buff[window:], input)
+
+	for idx := range hashHead {
+		hashHead[idx] = -1
+	}
+
+	var hash = func(pos int) (val uint32) {
+		for idx := range hashLen {
+			val = (val << 8) | uint32(buff[pos+idx])
+		}
+
+		return (val * 2654435761) >> (32 - hashBits)
+	}
+
+	var insert = func(pos int) {
+		if (pos + hashLen) > len(buff) {
+			return
+		}
+
+		var key = hash(pos)
+		hashPrev[pos] = hashHead[key]
+		hashHead[key] = int32(pos)
+	}
+
+	for pos := range window {
+		insert(pos)
+	}
+
+	output = make([]byte, 0, len(input)+(len(input)/8)+1)
+
+	for pos := window; pos < len(buff); {
+		// @Flag
+		// Reserve FL on very first loop, and every 9th loop after that
+
+		if flagsBit == 8 {
+			output = append(output, 0)
+			flagsIdx = len(output) - 1
+			flagsBit = 0
+		}
+
+		var (
+			matchLen int
+			matchPos int
+			limit    = min(maxMatch, len(buff)-pos)
+		)
+
+		if limit >= minMatch {
+			var chain = 0
+			for candidate := int(hashHead[hash(pos)]); candidate >= 0 && (pos-candidate) <= window && chain < hashChain; candidate = int(hashPrev[candidate]) {
+				chain++
+
+				var length = 0
+				for length < limit && buff[candidate+length] == buff[pos+length] {
+					length++
+				}
+
+				if length > matchLen {
+					matchLen, matchPos = length, candidate
+
+					if length == limit {
+						break
+					}
+				}
+			}
+		}
+
+		if matchLen >= minMatch {
+			// @FlagEven
+			// Write 2 bytes describing dictionary offset and length of data to copy
+
+			var dictionaryOffset = matchPos % dictionarySize
+
+			output = append(output, byte(dictionaryOffset), byte((dictionaryOffset>>4)&0xF0)|byte(matchLen-minMatch))
+
+			for range matchLen {
+				insert(pos)
+				pos++
+			}
+		} else {
+			// @FlagOdd
+			// Write raw byte
+
+			output[flagsIdx] |= 1 << flagsBit
+			output = append(output, buff[pos])
+
+			insert(pos)
+			pos++
+		}
+
+		// @FlagNext
+		flagsBit++
+	}
+
+	return output, nil
+}
@@ -0,0 +1,111 @@
+package lzss
+
+import (
+	"bytes"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+// DoData calls `callback` for each `Real` file found in `dat/testdata/extracted`, as well as
+// some generated data
+func DoData(t *testing.T, callback func(*testing.T, []byte)) {
+	t.Helper()
+
+	var random = rand.New(rand.NewSource(2238))
+	var data = map[string][]byte{
+		"Byte":    {'F'},
+		"Spaces":  bytes.Repeat([]byte{' '}, (FalloutBlockSize * 3)),
+		"Pattern": bytes.Repeat([]byte("Fallout"), (FalloutBlockSize / 2)),
+		"Random":  make([]byte, (FalloutBlockSize*2)+77),
+		"Text":    make([]byte, (FalloutBlockSize*4)+11),
+	}
+
+	random.Read(data["Random"])
+	for idx := range data["Text"] {
+		data["Text"][idx] = byte('a' + random.Intn(4))
+	}
+
+	var dir = filepath.Join("..", "..", "dat", "testdata", "extracted")
+	filepath.WalkDir(dir, func(filename string, dirEntry fs.DirEntry, err error) error {
+		if err != nil || dirEntry.IsDir() || dirEntry.Name() != "Real" {
+			return err
+		}
+
+		var name, _ = filepath.Rel(dir, filepath.Dir(filename))
+		data[filepath.ToSlash(name)], err = os.ReadFile(filename)
+
+		return err
+	})
+
+	for name, bytesReal := range data {
+		t.Run(name, func(t *testing.T) {
+			callback(t, bytesReal)
+		})
+	}
+}
+
+func TestCompress(t *testing.T) {
+	DoData(t, func(t *testing.T, bytesReal []byte) {
+		var (
+			err           error
+			bytesPacked   []byte
+			bytesUnpacked []byte
+		)
+
+		bytesPacked, err = falloutLZSS.Compress(bytesReal)
+		must.NoError(t, err)
+
+		bytesUnpacked, err = falloutLZSS.Decompress(bytes.NewReader(bytesPacked), int64(len(bytesPacked)))
+		must.NoError(t, err)
+
+		test.SliceEqFunc(t, bytesReal, bytesUnpacked, func(a, b byte) bool { return a == b })
+	})
+}
+
+func TestCompressEmpty(t *testing.T) {
+	var bytesPacked, err = falloutLZSS.Compress([]byte{})
+	must.NoError(t, err)
+	test.SliceEmpty(t, bytesPacked)
+}
+
+func TestCompressInvalid(t *testing.T) {
+	for _, lzss := range []LZSS{
+		{DictionarySize: 0, MinMatch: 3, MaxMatch: 18},
+		{DictionarySize: 4096, MinMatch: 0, MaxMatch: 18},
+		{DictionarySize: 4096, MinMatch: 3, MaxMatch: 0},
+		{DictionarySize: 4095, MinMatch: 3, MaxMatch: 18},
+		{DictionarySize: 8192, MinMatch: 3, MaxMatch: 18},
+		{DictionarySize: 4096, MinMatch: 4, MaxMatch: 3},
+		{DictionarySize: 16, MinMatch: 3, MaxMatch: 16},
+	} {
+		var _, err = lzss.Compress([]byte("Fallout"))
+		test.Error(t, err)
+	}
+}
+
+func TestCompressConfig(t *testing.T) {
+	var data = bytes.Repeat([]byte("War. War never changes. "), 100)
+
+	for _, lzss := range []LZSS{
+		Default,
+		{DictionarySize: 1024, MinMatch: 3, MaxMatch: 18},
+		{DictionarySize: 4096, MinMatch: 1, MaxMatch: 16},
+		{DictionarySize: 4096, MinMatch: 3, MaxMatch: 32},
+		{DictionarySize: 64, MinMatch: 2, MaxMatch: 4},
+	} {
+		var bytesPacked, err = lzss.Compress(data)
+		must.NoError(t, err)
+		test.Less(t, len(data), len(bytesPacked))
+
+		var bytesUnpacked []byte
+		bytesUnpacked, err = lzss.Decompress(bytes.NewReader(bytesPacked), int64(len(bytesPacked)))
+		must.NoError(t, err)
+		test.SliceEqFunc(t, data, bytesUnpacked, func(a, b byte) bool { return a == b })
+	}
+}
@@ -0,0 +1,97 @@
+// Package config reads engine configuration files, such as fallout.cfg, fallout2.cfg, or ddraw.ini
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const errPackage = "fo/config:"
+
+// Config holds content of configuration file; sections and keys names are lowercased,
+// as engine treats them case-insensitively
+type Config map[string]map[string]string
+
+// Parse reads configuration file, same way as engine does
+//
+//   - `;` starts a comment, which lasts until end of line
+//   - `[name]` starts a new section; keys before first section are ignored
+//   - `key=value` adds a key to current section, with whitespace around key and value removed;
+//     if key is repeated, last value is used
+//   - any other lines are ignored
+func Parse(reader io.Reader) (cfg Config, err error) {
+	var (
+		scanner = bufio.NewScanner(reader)
+		section map[string]string
+	)
+
+	cfg = make(Config)
+
+	for scanner.Scan() {
+		var line, _, _ = strings.Cut(scanner.Text(), ";")
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "[") {
+			var name, ok = strings.CutSuffix(line[1:], "]")
+			if !ok {
+				continue
+			}
+
+			name = strings.ToLower(strings.TrimSpace(name))
+			if section, ok = cfg[name]; !ok {
+				section = make(map[string]string)
+				cfg[name] = section
+			}
+
+			continue
+		}
+
+		var key, value, ok = strings.Cut(line, "=")
+		if !ok || section == nil {
+			continue
+		}
+
+		if key = strings.ToLower(strings.TrimSpace(key)); key == "" {
+			continue
+		}
+
+		section[key] = strings.TrimSpace(value)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s Parse() %w", errPackage, err)
+	}
+
+	return cfg, nil
+}
+
+// ReadFile reads configuration file with given name
+func ReadFile(filename string) (cfg Config, err error) {
+	var osFile *os.File
+
+	if osFile, err = os.Open(filename); err != nil {
+		return nil, fmt.Errorf("%s ReadFile() %w", errPackage, err)
+	}
+	defer osFile.Close()
+
+	return Parse(osFile)
+}
+
+// Get returns value of given key; lookup is case-insensitive
+func (cfg Config) Get(section string, key string) (value string, ok bool) {
+	value, ok = cfg[strings.ToLower(section)][strings.ToLower(key)]
+
+	return value, ok
+}
+
+// GetString returns value of given key, or `defaultValue` if key is missing or empty
+func (cfg Config) GetString(section string, key string, defaultValue string) string {
+	if value, ok := cfg.Get(section, key); ok && value != "" {
+		return value
+	}
+
+	return defaultValue
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+const fallout2cfg = `ignored=before section
+[system]
+executable=game
+master_dat=master.dat
+master_patches=data ; comment
+critter_dat = critter.dat
+critter_patches=data
+language=english
+scroll_lock=0
+interrupt_walk=1
+art_cache_size=8
+color_cycling=1
+hashing=1
+splash=0
+free_space=20480
+; commented=out
+times 2
+[ Sound ]
+initialize=1
+Music_Path1=sound\music\
+master_dat=ignored
+[sound]
+music_volume=22118
+music_volume=1
+[broken
+`
+
+func TestParse(t *testing.T) {
+	var cfg, err = Parse(strings.NewReader(fallout2cfg))
+	must.NoError(t, err)
+
+	test.MapLen(t, 2, cfg)
+	test.MapNotContainsKey(t, cfg, "")
+
+	for _, data := range []struct {
+		section, key, value string
+		ok                  bool
+	}{
+		{"system", "master_dat", "master.dat", true},
+		{"SYSTEM", "MASTER_PATCHES", "data", true},
+		{"system", "critter_dat", "critter.dat", true},
+		{"system", "ignored", "", false},
+		{"system", "commented", "", false},
+		{"system", "times 2", "", false},
+		{"sound", "music_path1", `sound\music\`, true},
+		{"sound", "master_dat", "ignored", true},
+		{"sound", "music_volume", "1", true},
+		{"missing", "master_dat", "", false},
+	} {
+		var value, ok = cfg.Get(data.section, data.key)
+		test.EqOp(t, data.ok, ok, test.Sprintf("[%s] %s", data.section, data.key))
+		test.EqOp(t, data.value, value, test.Sprintf("[%s] %s", data.section, data.key))
+	}
+
+	test.EqOp(t, "master.dat", cfg.GetString("system", "master_dat", "default"))
+	test.EqOp(t, "default", cfg.GetString("system", "missing", "default"))
+}
+
+func TestReadFile(t *testing.T) {
+	var filename = filepath.Join(t.TempDir(), "fallout2.cfg")
+	must.NoError(t, os.WriteFile(filename, []byte(strings.ReplaceAll(fallout2cfg, "\n", "\r\n")), 0644))
+
+	var cfg, err = ReadFile(filename)
+	must.NoError(t, err)
+	test.EqOp(t, "data", cfg.GetString("system", "master_patches", ""))
+
+	_, err = ReadFile(filepath.Join(t.TempDir(), "missing.cfg"))
+	test.Error(t, err)
+}
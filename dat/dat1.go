@@ -59,6 +59,8 @@ func (dat *falloutDatV1) readDat(stream io.ReadSeeker) (err error) {
 	}
 
 	// header validation
+	//
+	// Header[0] is most likely a capacity of engine's directories list
 	switch dat.Header[0] {
 	// known values
 	case 0x5E: // master.dat
@@ -71,8 +73,10 @@ func (dat *falloutDatV1) readDat(stream io.ReadSeeker) (err error) {
 	case 0:
 		return fmt.Errorf("%s header[0] = 0, %s", errPrefix, dbg.Fmt(" ", dat.Header))
 	default:
-		// ignore?
-		return fmt.Errorf("%s header[0] = unknown, %s", errPrefix, dbg.Fmt(" ", dat.Header))
+		// .dat files created by `WriteFallout1()` uses DirsCount
+		if dat.Header[0] < dat.DirsCount {
+			return fmt.Errorf("%s header[0] = unknown, %s", errPrefix, dbg.Fmt(" ", dat.Header))
+		}
 	}
 
 	if dat.Header[1] != 0 {
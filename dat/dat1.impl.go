@@ -124,6 +124,23 @@ func (file *falloutFileV1) GetBytesPacked(stream io.ReadSeeker) ([]byte, error)
 	return file.getBytesPacked(stream, file)
 }
 
+// GetBytesRealAt implements FalloutFile
+func (file *falloutFileV1) GetBytesRealAt(stream io.ReaderAt) ([]byte, error) {
+	return file.getBytesRealAt(stream, file)
+}
+
+// GetBytesPackedAt implements FalloutFile
+func (file *falloutFileV1) GetBytesPackedAt(stream io.ReaderAt) ([]byte, error) {
+	return file.getBytesPackedAt(stream, file)
+}
+
+// Open implements FalloutFile
+func (file *falloutFileV1) Open(stream io.ReaderAt) (io.ReadCloser, error) {
+	return file.open(stream, file, 1, func(reader io.Reader) (io.Reader, error) {
+		return lzss.NewFalloutReader(reader, file.GetSizePacked(), file.GetPackedMode())
+	})
+}
+
 func (file *falloutFileV1) GetBytesUnpacked(bytesPacked []byte) (bytesUnpacked []byte, err error) {
 	var lzssFile = lzss.FalloutFile{
 		Stream:       bytes.NewReader(bytesPacked),
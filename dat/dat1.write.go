@@ -0,0 +1,272 @@
+package dat
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/wipe2238/fo/compress/lzss"
+)
+
+// writeDat creates DAT1 from given files
+//
+// As DAT1 keeps its tree before files content, `stream` is rewinded to write tree after all
+// files content is known; on success, `stream` position is set to end of written data
+func (dat *falloutDatV1) writeDat(stream io.WriteSeeker, files []WriterFile, options WriterOptions) (err error) {
+	const errPrefix = errPackage + "writeDat(1)"
+
+	var entries []writerEntry
+
+	if err = options.validate(); err != nil {
+		return fmt.Errorf("%s %w", errPrefix, err)
+	}
+
+	if entries, err = writerPrepare(files); err != nil {
+		return fmt.Errorf("%s %w", errPrefix, err)
+	}
+
+	//
+	// Directories list
+	//
+
+	// Engine uses binary search when looking for directory/file entry, so both lists must be
+	// sorted case-insensitively, using names in exactly same format as they are saved
+
+	var (
+		dirsMap    = make(map[string]*falloutDirV1)
+		entriesMap = make(map[*falloutFileV1]writerEntry, len(entries))
+	)
+
+	dat.Dirs = make([]*falloutDirV1, 0)
+
+	for _, entry := range entries {
+		var dirPath = strings.ReplaceAll(entry.dir, "/", `\`)
+
+		if len(dirPath) > math.MaxUint8 {
+			return fmt.Errorf("%s directory name too long '%s'", errPrefix, dirPath)
+		} else if len(entry.name) > math.MaxUint8 {
+			return fmt.Errorf("%s file name too long '%s'", errPrefix, entry.Path)
+		}
+
+		// First file in newly discovered directory decides its case, same as in `falloutDatV2.makeDirs()`
+		var dir, ok = dirsMap[strings.ToUpper(dirPath)]
+		if !ok {
+			dir = &falloutDirV1{Path: dirPath, parentDat: dat}
+
+			dirsMap[strings.ToUpper(dirPath)] = dir
+			dat.Dirs = append(dat.Dirs, dir)
+		}
+
+		var file = &falloutFileV1{Name: entry.name, parentDir: dir}
+
+		entriesMap[file] = entry
+		dir.Files = append(dir.Files, file)
+	}
+
+	slices.SortFunc(dat.Dirs, func(a *falloutDirV1, b *falloutDirV1) int {
+		return strings.Compare(strings.ToLower(a.Path), strings.ToLower(b.Path))
+	})
+
+	for _, dir := range dat.Dirs {
+		slices.SortFunc(dir.Files, func(a *falloutFileV1, b *falloutFileV1) int {
+			return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		})
+
+		// Header[0] seems to work with most positive values, Header[1] is always 0x10
+		dir.FilesCount = int32(len(dir.Files))
+		dir.Header = [3]int32{dir.FilesCount, 0x10, 0}
+	}
+
+	// Header[0] must be a positive value (see DAT.html), Header[1] must be 0 (see `readDat()`)
+	dat.DirsCount = int32(len(dat.Dirs))
+	dat.Header = [3]int32{dat.DirsCount, 0, 0}
+
+	//
+	// Files content
+	//
+
+	var (
+		streamStart int64
+		sizeTree    = dat.sizeTree()
+		offset      = sizeTree
+	)
+
+	if streamStart, err = stream.Seek(0, io.SeekCurrent); err != nil {
+		return fmt.Errorf("%s cannot store stream position", errPrefix)
+	}
+
+	if _, err = stream.Seek(streamStart+sizeTree, io.SeekStart); err != nil {
+		return err
+	}
+
+	var writer = bufio.NewWriter(stream)
+
+	for _, file := range dat.files() {
+		var (
+			entry       = entriesMap[file]
+			bytesReal   []byte
+			bytesPacked []byte
+		)
+
+		if bytesReal, err = entry.readAll(); err != nil {
+			return fmt.Errorf("%s cannot read '%s': %w", errPrefix, entry.Path, err)
+		}
+
+		// Empty files are always stored as-is, as LZSS data cannot be empty
+		if options.Compress != CompressNever && len(bytesReal) > 0 {
+			var lzssFile = lzss.FalloutFile{CompressMode: lzss.FalloutCompressLZSS}
+
+			if bytesPacked, err = lzssFile.Compress(bytesReal); err != nil {
+				return fmt.Errorf("%s cannot compress '%s': %w", errPrefix, entry.Path, err)
+			}
+		}
+
+		if len(bytesReal) > 0 && options.usePacked(len(bytesReal), len(bytesPacked)) {
+			file.PackedMode = lzss.FalloutCompressLZSS
+			file.SizePacked = uint32(len(bytesPacked))
+		} else {
+			file.PackedMode = lzss.FalloutCompressNone
+			bytesPacked = bytesReal
+		}
+
+		if (offset+int64(len(bytesPacked))) > math.MaxUint32 || int64(len(bytesReal)) > math.MaxUint32 {
+			return fmt.Errorf("%s '%s' cannot be added, DAT1 size limit reached", errPrefix, entry.Path)
+		}
+
+		file.Offset = uint32(offset)
+		file.SizeReal = uint32(len(bytesReal))
+
+		if _, err = writer.Write(bytesPacked); err != nil {
+			return err
+		}
+
+		offset += int64(len(bytesPacked))
+	}
+
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+
+	//
+	// Tree
+	//
+
+	if _, err = stream.Seek(streamStart, io.SeekStart); err != nil {
+		return err
+	}
+
+	writer.Reset(stream)
+
+	if err = binary.Write(writer, binary.BigEndian, dat.DirsCount); err != nil {
+		return err
+	}
+
+	if err = binary.Write(writer, binary.BigEndian, dat.Header); err != nil {
+		return err
+	}
+
+	for _, dir := range dat.Dirs {
+		if err = dat.writeString(writer, dir.Path); err != nil {
+			return err
+		}
+	}
+
+	for _, dir := range dat.Dirs {
+		if err = dat.writeDir(writer, dir); err != nil {
+			return err
+		}
+	}
+
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+
+	// Leave stream at the end of .dat file
+	if _, err = stream.Seek(streamStart+offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (dat *falloutDatV1) writeDir(stream io.Writer, dir *falloutDirV1) (err error) {
+	if err = binary.Write(stream, binary.BigEndian, dir.FilesCount); err != nil {
+		return err
+	}
+
+	if err = binary.Write(stream, binary.BigEndian, dir.Header); err != nil {
+		return err
+	}
+
+	for _, file := range dir.Files {
+		if err = dat.writeFile(stream, file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (dat *falloutDatV1) writeFile(stream io.Writer, file *falloutFileV1) (err error) {
+	if err = dat.writeString(stream, file.Name); err != nil {
+		return err
+	}
+
+	for _, data := range []uint32{file.PackedMode, file.Offset, file.SizeReal, file.SizePacked} {
+		if err = binary.Write(stream, binary.BigEndian, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (dat *falloutDatV1) writeString(stream io.Writer, str string) (err error) {
+	if _, err = stream.Write([]byte{byte(len(str))}); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(stream, str)
+
+	return err
+}
+
+// sizeTree returns size of header and tree, which is also offset of first file content
+func (dat *falloutDatV1) sizeTree() (size int64) {
+	// DirsCount = 4
+	// Header    = 4 * 3
+	size = 16
+
+	for _, dir := range dat.Dirs {
+		// NameLength = 1
+		// Name      <- NameLength
+		// FilesCount = 4
+		// Header     = 4 * 3
+		size += int64(len(dir.Path)) + 17
+
+		for _, file := range dir.Files {
+			// NameLength = 1
+			// Name      <- NameLength
+			// PackedMode = 4
+			// Offset     = 4
+			// SizeReal   = 4
+			// SizePacked = 4
+			size += int64(len(file.Name)) + 17
+		}
+	}
+
+	return size
+}
+
+// files returns all files, in order used by DAT1 tree
+func (dat *falloutDatV1) files() (files []*falloutFileV1) {
+	for _, dir := range dat.Dirs {
+		files = append(files, dir.Files...)
+	}
+
+	return files
+}
@@ -85,6 +85,27 @@ func (dat *falloutDatV2) readDat(stream io.ReadSeeker) (err error) {
 		return err
 	}
 
+	var files = make([]*falloutFileV2, 0)
+
+	for range dat.FilesCount {
+		var file = new(falloutFileV2)
+
+		if err = dat.readFile(stream, file); err != nil {
+			return err
+		}
+
+		files = append(files, file)
+	}
+
+	dat.makeDirs(files)
+
+	return nil
+}
+
+// makeDirs fills `dat.Dirs` with given files, using their paths to invent directories
+//
+// Files order is preserved by setting `falloutFileV2.Index`
+func (dat *falloutDatV2) makeDirs(files []*falloutFileV2) {
 	// DAT2 does not have a concept of directories, so it needs to be invented
 	// While reading, we use map[string]* for quick lookup, plus []string for keys/directories names
 	// which will be sorted later
@@ -94,13 +115,7 @@ func (dat *falloutDatV2) readDat(stream io.ReadSeeker) (err error) {
 		dirsMapKeys = make([]string, 0)
 	)
 
-	for idxFile := range dat.FilesCount {
-		var file = new(falloutFileV2)
-
-		if err = dat.readFile(stream, file); err != nil {
-			return err
-		}
-
+	for idxFile, file := range files {
 		// First file in newly discovered directory decides if it's lowercased, uppercased, or mixed
 		// That's to prevent badly packed .dat files to either create duplicated FalloutDir objects,
 		// or creating N directories during extraction on case-sensitive operating systems
@@ -133,8 +148,6 @@ func (dat *falloutDatV2) readDat(stream io.ReadSeeker) (err error) {
 	for idx, dirNameUpper := range dirsMapKeys {
 		dat.Dirs[idx] = dirsMap[dirNameUpper]
 	}
-
-	return nil
 }
 
 func (dat *falloutDatV2) readFile(stream io.ReadSeeker, file *falloutFileV2) (err error) {
@@ -122,6 +122,23 @@ func (file *falloutFileV2) GetBytesPacked(stream io.ReadSeeker) ([]byte, error)
 	return file.getBytesPacked(stream, file)
 }
 
+// GetBytesRealAt implements FalloutFile
+func (file *falloutFileV2) GetBytesRealAt(stream io.ReaderAt) ([]byte, error) {
+	return file.getBytesRealAt(stream, file)
+}
+
+// GetBytesPackedAt implements FalloutFile
+func (file *falloutFileV2) GetBytesPackedAt(stream io.ReaderAt) ([]byte, error) {
+	return file.getBytesPackedAt(stream, file)
+}
+
+// Open implements FalloutFile
+func (file *falloutFileV2) Open(stream io.ReaderAt) (io.ReadCloser, error) {
+	return file.open(stream, file, 2, func(reader io.Reader) (io.Reader, error) {
+		return zlib.NewReader(reader)
+	})
+}
+
 func (file *falloutFileV2) GetBytesUnpacked(bytesPacked []byte) (bytesUnpacked []byte, err error) {
 	var bytesReader = bytes.NewReader(bytesPacked)
 
@@ -0,0 +1,166 @@
+package dat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// updateDat adds, replaces and removes files in existing DAT2
+//
+// New content is written in place of old tree, followed by new tree and footer; content of replaced
+// and removed files is not touched, and becomes unused. Replaced files keep their original path.
+//
+// All new content is read and compressed before `stream` is modified, so errors caused by `files`
+// leave .dat file unchanged. DAT2 with paths which differ only in case is not updated.
+//
+// On success, `stream` is truncated to size of updated .dat file, and `dat` contains same data
+// as if updated stream would be read by `readDat()`
+func (dat *falloutDatV2) updateDat(stream WriteTruncater, files []WriterFile, remove []string, options WriterOptions) (err error) {
+	const errPrefix = errPackage + "updateDat(2)"
+
+	var entries []writerEntry
+
+	if err = options.validate(); err != nil {
+		return fmt.Errorf("%s %w", errPrefix, err)
+	}
+
+	if len(files) == 0 && len(remove) == 0 {
+		return fmt.Errorf("%s nothing to update", errPrefix)
+	} else if len(files) > 0 {
+		if entries, err = writerPrepare(files); err != nil {
+			return fmt.Errorf("%s %w", errPrefix, err)
+		}
+	}
+
+	// All keys are cleaned up, uppercased, *nix paths
+	var (
+		key = func(filePath string) string {
+			return strings.ToUpper(path.Clean(strings.ReplaceAll(filePath, `\`, "/")))
+		}
+		datFiles = make(map[string]*falloutFileV2)
+	)
+
+	// Such files cannot be told apart when replacing or removing, and only one of them would be kept
+	for _, dir := range dat.Dirs {
+		for _, file := range dir.Files {
+			if fileOther, ok := datFiles[key(file.Path)]; ok {
+				return fmt.Errorf("%s paths of '%s' and '%s' differ only in case, .dat file must be repacked first", errPrefix, fileOther.Path, file.Path)
+			}
+
+			datFiles[key(file.Path)] = file
+		}
+	}
+
+	for _, filePath := range remove {
+		var filePathKey = key(filePath)
+
+		if _, ok := datFiles[filePathKey]; !ok {
+			return fmt.Errorf("%s cannot remove '%s', file not found", errPrefix, filePath)
+		}
+
+		if slices.ContainsFunc(entries, func(entry writerEntry) bool { return key(entry.Path) == filePathKey }) {
+			return fmt.Errorf("%s cannot both add and remove '%s'", errPrefix, filePath)
+		}
+
+		delete(datFiles, filePathKey)
+	}
+
+	//
+	// Data block
+	//
+
+	// New content is placed where old tree starts; until all files are read and compressed,
+	// it's kept in temporary file, so failed update leaves .dat file untouched
+	var (
+		treeStart = int64(dat.SizeDat) - int64(dat.SizeTree) - 8
+		offset    = treeStart
+		staging   *os.File
+	)
+
+	if staging, err = os.CreateTemp("", "fo-dat-update-"); err != nil {
+		return fmt.Errorf("%s %w", errPrefix, err)
+	}
+
+	defer func() {
+		staging.Close()
+		os.Remove(staging.Name())
+	}()
+
+	var writer = bufio.NewWriter(staging)
+
+	for _, entry := range entries {
+		var file *falloutFileV2
+
+		// Replaced files keep their original path
+		if file, ok := datFiles[key(entry.Path)]; ok {
+			entry.Path = file.Path
+		} else {
+			entry.Path = strings.ReplaceAll(entry.Path, "/", `\`)
+		}
+
+		if file, err = dat.writeEntry(writer, entry, offset, options); err != nil {
+			return fmt.Errorf("%s %w", errPrefix, err)
+		}
+
+		offset += int64(file.SizePacked)
+		datFiles[key(file.Path)] = file
+	}
+
+	if err = writer.Flush(); err != nil {
+		return fmt.Errorf("%s %w", errPrefix, err)
+	}
+
+	if _, err = staging.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("%s %w", errPrefix, err)
+	}
+
+	//
+	// Tree
+	//
+
+	// Engine uses binary search when looking for file entry, see `falloutDatV2.writeDat()`
+	var treeFiles = make([]*falloutFileV2, 0, len(datFiles))
+	for _, file := range datFiles {
+		treeFiles = append(treeFiles, file)
+	}
+
+	slices.SortFunc(treeFiles, func(a *falloutFileV2, b *falloutFileV2) int {
+		return strings.Compare(strings.ToLower(a.Path), strings.ToLower(b.Path))
+	})
+
+	if err = dat.checkTree(treeFiles, offset); err != nil {
+		return fmt.Errorf("%s %w", errPrefix, err)
+	}
+
+	// From this point, .dat file is modified
+	if _, err = stream.Seek(treeStart, io.SeekStart); err != nil {
+		return err
+	}
+
+	writer.Reset(stream)
+
+	if _, err = io.Copy(writer, staging); err != nil {
+		return err
+	}
+
+	if err = dat.writeTree(writer, treeFiles, offset); err != nil {
+		return fmt.Errorf("%s %w", errPrefix, err)
+	}
+
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+
+	if err = stream.Truncate(int64(dat.SizeDat)); err != nil {
+		return err
+	}
+
+	dat.makeDirs(treeFiles)
+
+	return nil
+}
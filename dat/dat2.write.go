@@ -0,0 +1,227 @@
+package dat
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+)
+
+// writeDat creates DAT2 from given files
+//
+// On success, `dat` contains same data as if created stream would be read by `readDat()`
+func (dat *falloutDatV2) writeDat(stream io.Writer, files []WriterFile, options WriterOptions) (err error) {
+	const errPrefix = errPackage + "writeDat(2)"
+
+	var entries []writerEntry
+
+	if err = options.validate(); err != nil {
+		return fmt.Errorf("%s %w", errPrefix, err)
+	}
+
+	if entries, err = writerPrepare(files); err != nil {
+		return fmt.Errorf("%s %w", errPrefix, err)
+	}
+
+	// Engine uses binary search when looking for file entry, so tree must be sorted
+	// case-insensitively, using paths in exactly same format as they are saved
+
+	for idx := range entries {
+		entries[idx].Path = strings.ReplaceAll(entries[idx].Path, "/", `\`)
+	}
+
+	slices.SortFunc(entries, func(a writerEntry, b writerEntry) int {
+		return strings.Compare(strings.ToLower(a.Path), strings.ToLower(b.Path))
+	})
+
+	var (
+		writer   = bufio.NewWriter(stream)
+		offset   int64
+		datFiles = make([]*falloutFileV2, 0, len(entries))
+	)
+
+	//
+	// Data block
+	//
+
+	for _, entry := range entries {
+		var file *falloutFileV2
+
+		if file, err = dat.writeEntry(writer, entry, offset, options); err != nil {
+			return fmt.Errorf("%s %w", errPrefix, err)
+		}
+
+		offset += int64(file.SizePacked)
+		datFiles = append(datFiles, file)
+	}
+
+	//
+	// Tree
+	//
+
+	if err = dat.writeTree(writer, datFiles, offset); err != nil {
+		return fmt.Errorf("%s %w", errPrefix, err)
+	}
+
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+
+	dat.makeDirs(datFiles)
+
+	return nil
+}
+
+// writeEntry writes (possibly compressed) file content, and returns its tree entry
+//
+// `offset` is a position at which content is written, relative to beginning of .dat file
+func (dat *falloutDatV2) writeEntry(stream io.Writer, entry writerEntry, offset int64, options WriterOptions) (file *falloutFileV2, err error) {
+	var (
+		bytesReal   []byte
+		bytesPacked []byte
+	)
+
+	file = &falloutFileV2{Path: entry.Path}
+
+	if bytesReal, err = entry.readAll(); err != nil {
+		return nil, fmt.Errorf("cannot read '%s': %w", entry.Path, err)
+	}
+
+	if options.Compress != CompressNever {
+		if bytesPacked, err = dat.compress(bytesReal, options.CompressLevel); err != nil {
+			return nil, fmt.Errorf("cannot compress '%s': %w", entry.Path, err)
+		}
+	}
+
+	if options.usePacked(len(bytesReal), len(bytesPacked)) {
+		file.PackedMode = 1
+	} else {
+		bytesPacked = bytesReal
+	}
+
+	if (offset+int64(len(bytesPacked))) > math.MaxUint32 || int64(len(bytesReal)) > math.MaxUint32 {
+		return nil, fmt.Errorf("'%s' cannot be added, DAT2 size limit reached", entry.Path)
+	}
+
+	file.Offset = uint32(offset)
+	file.SizeReal = uint32(len(bytesReal))
+	file.SizePacked = uint32(len(bytesPacked))
+
+	if _, err = stream.Write(bytesPacked); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// writeTree writes tree and footer, using files in given order
+//
+// `offset` is a size of data block, which is always placed right before tree
+func (dat *falloutDatV2) writeTree(stream io.Writer, files []*falloutFileV2, offset int64) (err error) {
+	if err = dat.checkTree(files, offset); err != nil {
+		return err
+	}
+
+	dat.FilesCount = uint32(len(files))
+	dat.SizeTree = uint32(dat.sizeTree(files))
+	dat.SizeDat = uint32(offset) + dat.SizeTree + 8
+
+	if err = binary.Write(stream, binary.LittleEndian, dat.FilesCount); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err = dat.writeFile(stream, file); err != nil {
+			return err
+		}
+	}
+
+	if err = binary.Write(stream, binary.LittleEndian, dat.SizeTree); err != nil {
+		return err
+	}
+
+	return binary.Write(stream, binary.LittleEndian, dat.SizeDat)
+}
+
+// sizeTree returns size of tree for given files, including FilesCount
+func (dat *falloutDatV2) sizeTree(files []*falloutFileV2) (size int64) {
+	size = 4 // FilesCount
+
+	for _, file := range files {
+		// PathLength = 4
+		// Path      <- PathLength
+		// PackedMode = 1
+		// SizeReal   = 4
+		// SizePacked = 4
+		// Offset     = 4
+		size += int64(len(file.Path) + 17)
+	}
+
+	return size
+}
+
+// checkTree returns error if tree for given files, starting at `offset`, would exceed DAT2 size limit
+func (dat *falloutDatV2) checkTree(files []*falloutFileV2, offset int64) error {
+	// SizeTree = 4
+	// SizeDat  = 4
+	if (offset + dat.sizeTree(files) + 8) > math.MaxUint32 {
+		return fmt.Errorf("DAT2 size limit reached")
+	}
+
+	return nil
+}
+
+func (dat *falloutDatV2) writeFile(stream io.Writer, file *falloutFileV2) (err error) {
+	if err = dat.writeString(stream, file.Path); err != nil {
+		return err
+	}
+
+	for _, data := range []any{file.PackedMode, file.SizeReal, file.SizePacked, file.Offset} {
+		if err = binary.Write(stream, binary.LittleEndian, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (dat *falloutDatV2) writeString(stream io.Writer, str string) (err error) {
+	if err = binary.Write(stream, binary.LittleEndian, uint32(len(str))); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(stream, str)
+
+	return err
+}
+
+// compress returns data compressed with zlib
+func (dat *falloutDatV2) compress(bytesReal []byte, level int) (bytesPacked []byte, err error) {
+	var (
+		buff    bytes.Buffer
+		zstream *zlib.Writer
+	)
+
+	if level == 0 {
+		level = zlib.BestCompression
+	}
+
+	if zstream, err = zlib.NewWriterLevel(&buff, level); err != nil {
+		return nil, err
+	}
+
+	if _, err = zstream.Write(bytesReal); err != nil {
+		return nil, err
+	}
+
+	if err = zstream.Close(); err != nil {
+		return nil, err
+	}
+
+	return buff.Bytes(), nil
+}
@@ -1,6 +1,7 @@
 package dat
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
 	"fmt"
@@ -355,6 +356,8 @@ func TestImpl(testingT *testing.T) {
 			CheckExtract(tb, stream, file, fileSource, "testdata/extracted")
 			CheckExtracted(tb, stream, file, fileSource, "testdata/extracted")
 			CheckUndatUI(tb, stream, file, fileSource, "testdata/undatui/data")
+			CheckOpen(tb, stream, file)
+			CheckBytesAt(tb, stream, file)
 		},
 	)
 }
@@ -567,3 +570,68 @@ func CheckUndatUI(tb testing.TB, stream io.ReadSeeker, file FalloutFile, fileSou
 		}
 	})
 }
+
+// CheckOpen compares data returned by `FalloutFile.Open()` and `FalloutFile.GetBytesReal()`
+func CheckOpen(tb testing.TB, stream io.ReadSeeker, file FalloutFile) {
+	var streamAt, ok = stream.(io.ReaderAt)
+	if !ok {
+		return
+	}
+
+	DoRunTB(tb, "Open", func(tb testing.TB) {
+		var (
+			err       error
+			reader    io.ReadCloser
+			bytesOpen []byte
+			bytesReal []byte
+		)
+
+		bytesReal, err = file.GetBytesReal(stream)
+		must.NoError(tb, err)
+
+		reader, err = file.Open(streamAt)
+		must.NoError(tb, err)
+
+		// small buffer makes sure reader works with partial reads
+		bytesOpen, err = io.ReadAll(bufio.NewReaderSize(reader, 16))
+		must.NoError(tb, err)
+		must.NoError(tb, reader.Close())
+
+		test.SliceEqFunc(tb, bytesReal, bytesOpen, func(a, b byte) bool { return a == b })
+	})
+}
+
+// CheckBytesAt compares data returned by `FalloutFile.GetBytes*At()` and their `io.ReadSeeker` counterparts
+func CheckBytesAt(tb testing.TB, stream io.ReadSeeker, file FalloutFile) {
+	var streamAt, ok = stream.(io.ReaderAt)
+	if !ok {
+		return
+	}
+
+	DoRunTB(tb, "BytesAt", func(tb testing.TB) {
+		var (
+			fn   = [2]func(FalloutFile, io.ReadSeeker) ([]byte, error){FalloutFile.GetBytesReal, FalloutFile.GetBytesPacked}
+			fnAt = [2]func(FalloutFile, io.ReaderAt) ([]byte, error){FalloutFile.GetBytesRealAt, FalloutFile.GetBytesPackedAt}
+		)
+
+		for idx := range fn {
+			var bytes, err = fn[idx](file, stream)
+			must.NoError(tb, err)
+
+			var pos int64
+			pos, err = stream.Seek(0, io.SeekCurrent)
+			must.NoError(tb, err)
+
+			var bytesAt []byte
+			bytesAt, err = fnAt[idx](file, streamAt)
+			must.NoError(tb, err)
+
+			test.SliceEqFunc(tb, bytes, bytesAt, func(a, b byte) bool { return a == b })
+
+			var posAt int64
+			posAt, err = stream.Seek(0, io.SeekCurrent)
+			must.NoError(tb, err)
+			test.EqOp(tb, pos, posAt)
+		}
+	})
+}
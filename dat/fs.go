@@ -0,0 +1,329 @@
+package dat
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FalloutFS provides access to .dat file content via `fs.FS` interface
+//
+// Lookups are case-insensitive, same as in engine; directories which are not a part of .dat file
+// (such as all DAT2 directories, or DAT1 parent directories) are created automatically.
+// Files which cannot be reached by path are not visible, see `FalloutFS.Skipped()`.
+//
+// Implements `fs.FS`, `fs.ReadDirFS`, `fs.ReadFileFS`, `fs.StatFS`
+type FalloutFS struct {
+	stream  io.ReaderAt
+	root    *fsNode
+	skipped []FalloutFile
+}
+
+// fsNode represents a single file or directory
+type fsNode struct {
+	name string
+	file FalloutFile // nil for directories
+
+	children     map[string]*fsNode // keys are uppercased
+	childrenList []*fsNode          // sorted by name
+}
+
+// fsFileInfo implements `fs.FileInfo`
+type fsFileInfo struct {
+	node *fsNode
+}
+
+// fsFile implements `fs.File`, `io.Seeker`, `io.ReaderAt`
+type fsFile struct {
+	*bytes.Reader
+
+	info fsFileInfo
+}
+
+// fsDir implements `fs.ReadDirFile`
+type fsDir struct {
+	info   fsFileInfo
+	offset int
+}
+
+var (
+	_ fs.FS         = (*FalloutFS)(nil)
+	_ fs.ReadDirFS  = (*FalloutFS)(nil)
+	_ fs.ReadFileFS = (*FalloutFS)(nil)
+	_ fs.StatFS     = (*FalloutFS)(nil)
+)
+
+// FS returns `FalloutFS` using already opened .dat file
+//
+// `stream` must be the same stream which was used to read `dat`; it must stay open
+// as long as `FalloutFS` is in use. As `stream` position is never changed, `FalloutFS`
+// is safe to use from multiple goroutines.
+//
+// If paths of two files differ by case only, first one is used; if directory path conflicts
+// with file path, all files in that directory are skipped. Skipped files are available
+// via `FalloutFS.Skipped()`
+func FS(dat FalloutDat, stream io.ReaderAt) *FalloutFS {
+	var fsys = &FalloutFS{
+		stream: stream,
+		root:   newFsNode("."),
+	}
+
+	for _, dir := range dat.GetDirs() {
+		var parent = fsys.root.mkdirAll(dir.GetPath())
+		if parent == nil {
+			// directory path conflicts with file path
+			fsys.skipped = append(fsys.skipped, dir.GetFiles()...)
+			continue
+		}
+
+		for _, file := range dir.GetFiles() {
+			if _, ok := parent.children[strings.ToUpper(file.GetName())]; ok {
+				fsys.skipped = append(fsys.skipped, file)
+				continue
+			}
+
+			var child = newFsNode(file.GetName())
+			child.file = file
+			parent.add(child)
+		}
+	}
+
+	return fsys
+}
+
+// Skipped returns files which are not visible, as their paths conflict with other files
+func (fsys *FalloutFS) Skipped() []FalloutFile {
+	return fsys.skipped
+}
+
+// Open implements `fs.FS`
+func (fsys *FalloutFS) Open(name string) (fs.File, error) {
+	var node, err = fsys.find("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if node.file == nil {
+		return &fsDir{info: fsFileInfo{node}}, nil
+	}
+
+	var bytesReal []byte
+	if bytesReal, err = fsys.readFile(node); err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return &fsFile{Reader: bytes.NewReader(bytesReal), info: fsFileInfo{node}}, nil
+}
+
+// ReadDir implements `fs.ReadDirFS`
+func (fsys *FalloutFS) ReadDir(name string) (entries []fs.DirEntry, err error) {
+	var node *fsNode
+	if node, err = fsys.find("readdir", name); err != nil {
+		return nil, err
+	}
+
+	if node.file != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	return node.dirEntries(), nil
+}
+
+// ReadFile implements `fs.ReadFileFS`
+func (fsys *FalloutFS) ReadFile(name string) (bytesReal []byte, err error) {
+	var node *fsNode
+	if node, err = fsys.find("readfile", name); err != nil {
+		return nil, err
+	}
+
+	if node.file == nil {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if bytesReal, err = fsys.readFile(node); err != nil {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: err}
+	}
+
+	return bytesReal, nil
+}
+
+// Stat implements `fs.StatFS`
+func (fsys *FalloutFS) Stat(name string) (fs.FileInfo, error) {
+	var node, err = fsys.find("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	return fsFileInfo{node}, nil
+}
+
+// find returns node with given name; lookup is case-insensitive
+func (fsys *FalloutFS) find(op string, name string) (node *fsNode, err error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	node = fsys.root
+	if name == "." {
+		return node, nil
+	}
+
+	for _, nodeName := range strings.Split(name, "/") {
+		var ok bool
+		if node, ok = node.children[strings.ToUpper(nodeName)]; !ok {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+	}
+
+	return node, nil
+}
+
+func (fsys *FalloutFS) readFile(node *fsNode) ([]byte, error) {
+	return node.file.GetBytesRealAt(fsys.stream)
+}
+
+//
+
+func newFsNode(name string) *fsNode {
+	return &fsNode{name: name, children: make(map[string]*fsNode)}
+}
+
+// mkdirAll returns node for given directory path, creating it if needed;
+// returns nil if any part of path is a file
+func (node *fsNode) mkdirAll(dirPath string) *fsNode {
+	for _, name := range strings.Split(path.Clean(dirPath), "/") {
+		if name == "." {
+			continue
+		}
+
+		// First file/directory decides directory case, see `falloutDatV2.makeDirs()`
+		var child, ok = node.children[strings.ToUpper(name)]
+		if !ok {
+			child = newFsNode(name)
+			node.add(child)
+		} else if child.file != nil {
+			return nil
+		}
+
+		node = child
+	}
+
+	return node
+}
+
+func (node *fsNode) add(child *fsNode) {
+	node.children[strings.ToUpper(child.name)] = child
+
+	var idx, _ = slices.BinarySearchFunc(node.childrenList, child.name, func(a *fsNode, name string) int {
+		return strings.Compare(a.name, name)
+	})
+
+	node.childrenList = slices.Insert(node.childrenList, idx, child)
+}
+
+func (node *fsNode) dirEntries() (entries []fs.DirEntry) {
+	entries = make([]fs.DirEntry, len(node.childrenList))
+
+	for idx, child := range node.childrenList {
+		entries[idx] = fs.FileInfoToDirEntry(fsFileInfo{child})
+	}
+
+	return entries
+}
+
+//
+
+// Name implements `fs.FileInfo`
+func (info fsFileInfo) Name() string {
+	return info.node.name
+}
+
+// Size implements `fs.FileInfo`
+func (info fsFileInfo) Size() int64 {
+	if info.node.file == nil {
+		return 0
+	}
+
+	return info.node.file.GetSizeReal()
+}
+
+// Mode implements `fs.FileInfo`
+func (info fsFileInfo) Mode() fs.FileMode {
+	if info.node.file == nil {
+		return fs.ModeDir | 0555
+	}
+
+	return 0444
+}
+
+// ModTime implements `fs.FileInfo`; .dat files do not keep modification time
+func (info fsFileInfo) ModTime() time.Time {
+	return time.Time{}
+}
+
+// IsDir implements `fs.FileInfo`
+func (info fsFileInfo) IsDir() bool {
+	return info.node.file == nil
+}
+
+// Sys implements `fs.FileInfo`; returns `FalloutFile` for files, and nil for directories
+func (info fsFileInfo) Sys() any {
+	if info.node.file == nil {
+		return nil
+	}
+
+	return info.node.file
+}
+
+//
+
+// Stat implements `fs.File`
+func (file *fsFile) Stat() (fs.FileInfo, error) {
+	return file.info, nil
+}
+
+// Close implements `fs.File`
+func (file *fsFile) Close() error {
+	return nil
+}
+
+//
+
+// Stat implements `fs.File`
+func (dir *fsDir) Stat() (fs.FileInfo, error) {
+	return dir.info, nil
+}
+
+// Read implements `fs.File`
+func (dir *fsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: dir.info.Name(), Err: fs.ErrInvalid}
+}
+
+// Close implements `fs.File`
+func (dir *fsDir) Close() error {
+	return nil
+}
+
+// ReadDir implements `fs.ReadDirFile`
+func (dir *fsDir) ReadDir(count int) (entries []fs.DirEntry, err error) {
+	var all = dir.info.node.dirEntries()[dir.offset:]
+
+	if count <= 0 {
+		dir.offset += len(all)
+
+		return all, nil
+	}
+
+	if len(all) == 0 {
+		return nil, io.EOF
+	}
+
+	entries = all[:min(count, len(all))]
+	dir.offset += len(entries)
+
+	return entries, nil
+}
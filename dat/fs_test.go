@@ -0,0 +1,145 @@
+package dat
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+func TestFS(t *testing.T) {
+	var files = append(WriterFilesExtracted(t, 1),
+		WriterFileBytes("COLOR.PAL", []byte("palette")),
+		WriterFileBytes(`TEXT\ENGLISH\GAME\MISC.MSG`, []byte("{100}{}{Fallout}")),
+	)
+
+	var expected = make([]string, 0, len(files))
+	for _, file := range files {
+		expected = append(expected, strings.ReplaceAll(file.Path, `\`, "/"))
+	}
+
+	t.Run("DAT1", func(t *testing.T) {
+		var osFile, err = os.Create(filepath.Join(t.TempDir(), "test.dat"))
+		must.NoError(t, err)
+		defer osFile.Close()
+
+		_, err = WriteFallout1(osFile, files, WriterOptions{})
+		must.NoError(t, err)
+
+		_, err = osFile.Seek(0, io.SeekStart)
+		must.NoError(t, err)
+
+		var dat FalloutDat
+		dat, err = Fallout1(osFile)
+		must.NoError(t, err)
+
+		CheckFS(t, FS(dat, osFile), files, expected)
+	})
+
+	t.Run("DAT2", func(t *testing.T) {
+		var buff bytes.Buffer
+
+		var _, err = WriteFallout2(&buff, files, WriterOptions{})
+		must.NoError(t, err)
+
+		var stream = bytes.NewReader(buff.Bytes())
+
+		var dat FalloutDat
+		dat, err = Fallout2(stream)
+		must.NoError(t, err)
+
+		CheckFS(t, FS(dat, stream), files, expected)
+	})
+}
+
+func TestFSSkipped(t *testing.T) {
+	var buff bytes.Buffer
+
+	var _, err = WriteFallout2(&buff, []WriterFile{
+		WriterFileBytes("DATA/FILE1.TXT", []byte("file1")),
+		WriterFileBytes("DATA/FILE2.TXT", []byte("file2")),
+		WriterFileBytes("DATA/FILE1.TXX/SUB.TXT", []byte("sub")),
+	}, WriterOptions{})
+	must.NoError(t, err)
+
+	// DAT writer refuses to create such files, tree needs to be patched manually
+	var data = bytes.Replace(buff.Bytes(), []byte(`DATA\FILE2.TXT`), []byte(`DATA\file1.txt`), 1)
+	data = bytes.Replace(data, []byte(`DATA\FILE1.TXX`), []byte(`DATA\FILE1.TXT`), 1)
+
+	var dat FalloutDat
+	dat, err = Fallout2(bytes.NewReader(data))
+	must.NoError(t, err)
+
+	var fsys = FS(dat, bytes.NewReader(data))
+
+	var skipped []string
+	for _, file := range fsys.Skipped() {
+		skipped = append(skipped, file.GetPath())
+	}
+
+	test.SliceContainsAll(t, []string{"DATA/file1.txt", "DATA/FILE1.TXT/SUB.TXT"}, skipped)
+
+	var bytesFS []byte
+	bytesFS, err = fs.ReadFile(fsys, "data/file1.txt")
+	must.NoError(t, err)
+	test.EqOp(t, "file1", string(bytesFS))
+}
+
+func CheckFS(t *testing.T, fsys *FalloutFS, files []WriterFile, expected []string) {
+	t.Helper()
+
+	must.NoError(t, fstest.TestFS(fsys, expected...))
+
+	for _, file := range files {
+		var bytesFile, err = file.readAll()
+		must.NoError(t, err)
+
+		for _, name := range []string{strings.ToLower(file.Path), strings.ToUpper(file.Path)} {
+			var bytesFS []byte
+
+			name = strings.ReplaceAll(name, `\`, "/")
+
+			bytesFS, err = fs.ReadFile(fsys, name)
+			must.NoError(t, err)
+			test.SliceEqFunc(t, bytesFile, bytesFS, func(a, b byte) bool { return a == b })
+
+			var info fs.FileInfo
+			info, err = fs.Stat(fsys, name)
+			must.NoError(t, err)
+			test.EqOp(t, int64(len(bytesFile)), info.Size())
+			test.False(t, info.IsDir())
+			test.EqOp(t, path.Base(strings.ReplaceAll(file.Path, `\`, "/")), info.Name())
+			test.NotNil(t, info.Sys())
+		}
+	}
+
+	// Directories without own entry
+	for _, name := range []string{"text", "TEXT/english", "art"} {
+		var info, err = fs.Stat(fsys, name)
+		must.NoError(t, err)
+		test.True(t, info.IsDir())
+	}
+
+	var matches, err = fs.Glob(fsys, "ART/*/*.LST")
+	must.NoError(t, err)
+	test.SliceNotEmpty(t, matches)
+
+	for _, name := range []string{"missing", "TEXT/missing", "COLOR.PAL/missing", "/COLOR.PAL", "../COLOR.PAL", "TEXT/"} {
+		_, err = fsys.Open(name)
+		test.Error(t, err)
+	}
+
+	_, err = fsys.ReadFile("TEXT")
+	test.Error(t, err)
+
+	_, err = fsys.ReadDir("COLOR.PAL")
+	test.Error(t, err)
+}
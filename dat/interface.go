@@ -73,6 +73,16 @@ type FalloutFile interface {
 	GetBytesPacked(io.ReadSeeker) ([]byte, error)
 	GetBytesUnpacked([]byte) ([]byte, error)
 
+	// GetBytesRealAt and GetBytesPackedAt works same as their `io.ReadSeeker` counterparts,
+	// but never changes stream position; safe to use with single stream from multiple goroutines
+	GetBytesRealAt(io.ReaderAt) ([]byte, error)
+	GetBytesPackedAt(io.ReaderAt) ([]byte, error)
+
+	// Open returns reader which unpacks file data on the fly, without loading whole file in memory
+	//
+	// `stream` must stay open until reader is closed
+	Open(stream io.ReaderAt) (io.ReadCloser, error)
+
 	GetDbg() dbg.Map
 
 	// SetDbg adds various debug info
@@ -127,3 +137,99 @@ func Fallout2(stream io.ReadSeeker) (dat2 FalloutDat, err error) {
 
 	return dat2, nil
 }
+
+// WriteFallout1 creates DAT1 from given files
+//
+// On success, returns `FalloutDat` object describing created .dat file;
+// to extract files data, created .dat file needs to be opened for reading.
+func WriteFallout1(stream io.WriteSeeker, files []WriterFile, options WriterOptions) (dat1 FalloutDat, err error) {
+	var datV1 = new(falloutDatV1)
+
+	if err = datV1.writeDat(stream, files, options); err != nil {
+		return nil, fmt.Errorf("%s WriteFallout1() %w", errPackage, err)
+	}
+
+	return datV1, nil
+}
+
+// WriteFallout2 creates DAT2 from given files
+//
+// On success, returns `FalloutDat` object describing created .dat file;
+// to extract files data, created .dat file needs to be opened for reading.
+func WriteFallout2(stream io.Writer, files []WriterFile, options WriterOptions) (dat2 FalloutDat, err error) {
+	var datV2 = new(falloutDatV2)
+
+	if err = datV2.writeDat(stream, files, options); err != nil {
+		return nil, fmt.Errorf("%s WriteFallout2() %w", errPackage, err)
+	}
+
+	return datV2, nil
+}
+
+// WriteTruncater is implemented by streams which can be written and truncated, such as `*os.File`
+type WriteTruncater interface {
+	io.WriteSeeker
+	Truncate(size int64) error
+}
+
+// UpdateFallout2 adds, replaces and removes files in existing DAT2, without rewriting unchanged files
+//
+// `stream` must be the same stream which was used to read `dat2`; `files` are added, or replace existing
+// files with same path (case-insensitive, original path is kept), `remove` is a list of paths of files to remove.
+//
+// Content of replaced and removed files is not removed from .dat file, and becomes unused space;
+// use `WriteFallout2()` to create .dat file without unused space. New content is prepared in a temporary
+// file, and .dat file is modified only after all `files` have been read and compressed; if writing
+// to `stream` itself fails (or is interrupted), .dat file is left in unusable state. If paths of any files
+// in `dat2` differ only in case, update fails; such .dat file needs to be recreated with `WriterFilesDat()` first.
+// On success, returns `FalloutDat` object describing updated .dat file; `dat2` should not be used anymore.
+func UpdateFallout2(stream WriteTruncater, dat2 FalloutDat, files []WriterFile, remove []string, options WriterOptions) (dat2Updated FalloutDat, err error) {
+	var datV2, ok = dat2.(*falloutDatV2)
+	if !ok {
+		return nil, fmt.Errorf("%s UpdateFallout2() DAT2 required", errPackage)
+	}
+
+	if err = datV2.updateDat(stream, files, remove, options); err != nil {
+		return nil, fmt.Errorf("%s UpdateFallout2() %w", errPackage, err)
+	}
+
+	return datV2, nil
+}
+
+// ConvertFallout1 creates DAT2 using content of DAT1
+//
+// `stream` must be the same stream which was used to read `dat1`. LZSS-compressed files are unpacked
+// and stored according to `options` (zlib-compressed by default); DAT1 directories are flattened into
+// DAT2 paths, keeping original case of both directories and files names. If paths of two files differ
+// by case only, first one is used, see `WriterFilesDat()`.
+func ConvertFallout1(stream io.ReaderAt, dat1 FalloutDat, output io.Writer, options WriterOptions) (dat2 FalloutDat, err error) {
+	var datV1, ok = dat1.(*falloutDatV1)
+	if !ok {
+		return nil, fmt.Errorf("%s ConvertFallout1() DAT1 required", errPackage)
+	}
+
+	var files, _ = WriterFilesDat(stream, datV1)
+
+	var datV2 = new(falloutDatV2)
+
+	if err = datV2.writeDat(output, files, options); err != nil {
+		return nil, fmt.Errorf("%s ConvertFallout1() %w", errPackage, err)
+	}
+
+	return datV2, nil
+}
+
+// DataRange returns offsets of area where files content is stored, `end` is exclusive
+//
+// DAT1 content is placed between tree and end of .dat file, DAT2 content is placed
+// between start of .dat file and tree; `sizeDat` is a length of .dat file.
+func DataRange(dat FalloutDat, sizeDat int64) (start int64, end int64) {
+	switch datV := dat.(type) {
+	case *falloutDatV1:
+		return datV.sizeTree(), sizeDat
+	case *falloutDatV2:
+		return 0, int64(datV.SizeDat) - int64(datV.SizeTree) - 8
+	}
+
+	return 0, sizeDat
+}
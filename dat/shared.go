@@ -1,6 +1,9 @@
 package dat
 
-import "io"
+import (
+	"fmt"
+	"io"
+)
 
 type falloutShared struct{}
 
@@ -37,9 +40,83 @@ func (shared falloutShared) getBytesPacked(stream io.ReadSeeker, file FalloutFil
 	}
 
 	bytes = make([]byte, file.GetSizePacked())
-	if _, err = stream.Read(bytes); err != nil {
+	if _, err = io.ReadFull(stream, bytes); err != nil {
 		return nil, err
 	}
 
 	return bytes, nil
 }
+
+func (shared falloutShared) getBytesRealAt(stream io.ReaderAt, file FalloutFile) (bytesReal []byte, err error) {
+	if bytesReal, err = shared.getBytesPackedAt(stream, file); err != nil {
+		return nil, err
+	}
+
+	if !file.GetPacked() {
+		return bytesReal, nil
+	}
+
+	return file.GetBytesUnpacked(bytesReal)
+}
+
+func (falloutShared) getBytesPackedAt(stream io.ReaderAt, file FalloutFile) (bytes []byte, err error) {
+	var size int
+
+	bytes = make([]byte, file.GetSizePacked())
+
+	// `io.ReaderAt` always returns an error if it cannot fill whole buffer,
+	// but it's also allowed to return `io.EOF` when buffer is filled
+	if size, err = stream.ReadAt(bytes, file.GetOffset()); size < len(bytes) {
+		return nil, err
+	}
+
+	return bytes, nil
+}
+
+// open returns reader limited to file data, with `unpack` applied if file is packed
+//
+// Returned reader makes sure that amount of unpacked data is the same as file size
+func (falloutShared) open(stream io.ReaderAt, file FalloutFile, game uint8, unpack func(io.Reader) (io.Reader, error)) (reader io.ReadCloser, err error) {
+	var (
+		section    = io.NewSectionReader(stream, file.GetOffset(), file.GetSizePacked())
+		readerReal io.Reader
+	)
+
+	if !file.GetPacked() {
+		return io.NopCloser(section), nil
+	}
+
+	if readerReal, err = unpack(section); err != nil {
+		return nil, err
+	}
+
+	return &sizeReader{reader: readerReal, size: file.GetSizeReal(), game: game}, nil
+}
+
+// sizeReader returns an error if amount of data read differs from `size`
+type sizeReader struct {
+	reader io.Reader
+	size   int64
+	game   uint8
+}
+
+// Read implements `io.Reader`
+func (reader *sizeReader) Read(buff []byte) (size int, err error) {
+	size, err = reader.reader.Read(buff)
+	reader.size -= int64(size)
+
+	if reader.size < 0 || (err == io.EOF && reader.size != 0) {
+		return size, fmt.Errorf("%s[%d] decompressed size mismatch", errPackage, reader.game)
+	}
+
+	return size, err
+}
+
+// Close implements `io.Closer`
+func (reader *sizeReader) Close() error {
+	if closer, ok := reader.reader.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package dat
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+// TestConcurrent extracts all files from a single `os.File`, using multiple goroutines
+func TestConcurrent(t *testing.T) {
+	for game, write := range map[uint8]func(io.WriteSeeker, []WriterFile, WriterOptions) (FalloutDat, error){
+		1: WriteFallout1,
+		2: func(stream io.WriteSeeker, files []WriterFile, options WriterOptions) (FalloutDat, error) {
+			return WriteFallout2(stream, files, options)
+		},
+	} {
+		t.Run(fmt.Sprintf("DAT%d", game), func(t *testing.T) {
+			var (
+				err    error
+				files  = WriterFilesExtracted(t, game)
+				osFile *os.File
+				dat    FalloutDat
+			)
+
+			osFile, err = os.Create(filepath.Join(t.TempDir(), "test.dat"))
+			must.NoError(t, err)
+			defer osFile.Close()
+
+			dat, err = write(osFile, files, WriterOptions{})
+			must.NoError(t, err)
+
+			var (
+				datFiles = make([]FalloutFile, 0)
+				expected = make(map[FalloutFile][]byte)
+			)
+
+			for _, dir := range dat.GetDirs() {
+				for _, file := range dir.GetFiles() {
+					expected[file], err = file.GetBytesReal(osFile)
+					must.NoError(t, err)
+
+					datFiles = append(datFiles, file)
+				}
+			}
+
+			var (
+				wait sync.WaitGroup
+				errs = make(chan error, (len(datFiles) * 8))
+			)
+
+			for worker := range 8 {
+				wait.Add(1)
+
+				go func() {
+					defer wait.Done()
+
+					for idx := range datFiles {
+						var (
+							file      = datFiles[(idx+worker)%len(datFiles)]
+							bytesReal []byte
+							reader    io.ReadCloser
+							err       error
+						)
+
+						if bytesReal, err = file.GetBytesRealAt(osFile); err != nil {
+							errs <- err
+							continue
+						} else if string(bytesReal) != string(expected[file]) {
+							errs <- fmt.Errorf("%s: GetBytesRealAt() mismatch", file.GetPath())
+							continue
+						}
+
+						if reader, err = file.Open(osFile); err != nil {
+							errs <- err
+							continue
+						}
+
+						bytesReal, err = io.ReadAll(reader)
+						reader.Close()
+
+						if err != nil {
+							errs <- err
+						} else if string(bytesReal) != string(expected[file]) {
+							errs <- fmt.Errorf("%s: Open() mismatch", file.GetPath())
+						}
+					}
+				}()
+			}
+
+			wait.Wait()
+			close(errs)
+
+			for err = range errs {
+				test.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package dat
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+// updateTest creates DAT2 with given files, and returns it opened for reading and writing
+func updateTest(tb testing.TB, files []WriterFile) (osFile *os.File, dat FalloutDat) {
+	tb.Helper()
+
+	var err error
+
+	osFile, err = os.OpenFile(filepath.Join(tb.TempDir(), "test.dat"), os.O_RDWR|os.O_CREATE, 0644)
+	must.NoError(tb, err)
+	tb.Cleanup(func() { osFile.Close() })
+
+	_, err = WriteFallout2(osFile, files, WriterOptions{})
+	must.NoError(tb, err)
+
+	_, err = osFile.Seek(0, io.SeekStart)
+	must.NoError(tb, err)
+
+	dat, err = Fallout2(osFile)
+	must.NoError(tb, err)
+
+	return osFile, dat
+}
+
+func TestUpdateFallout2(t *testing.T) {
+	var files = []WriterFile{
+		WriterFileBytes("ART/CRITTERS/HAPOWERA.FRM", bytes.Repeat([]byte("frm"), 100)),
+		WriterFileBytes("DATA/REMOVE.TXT", []byte("remove")),
+		WriterFileBytes("TEXT/ENGLISH/GAME/MISC.MSG", []byte("{100}{}{Fallout}")),
+	}
+
+	var osFile, dat = updateTest(t, files)
+
+	var offsets = make(map[string]int64)
+	for _, dir := range dat.GetDirs() {
+		for _, file := range dir.GetFiles() {
+			offsets[file.GetPath()] = file.GetOffset()
+		}
+	}
+
+	var (
+		err       error
+		datUpdate FalloutDat
+		datRead   FalloutDat
+	)
+
+	datUpdate, err = UpdateFallout2(osFile, dat, []WriterFile{
+		WriterFileBytes("text/english/game/misc.msg", []byte("{100}{}{Fallout 2}")),
+		WriterFileBytes("DATA/ADDED.TXT", bytes.Repeat([]byte("added"), 100)),
+	}, []string{`data\remove.txt`}, WriterOptions{})
+	must.NoError(t, err)
+
+	_, err = osFile.Seek(0, io.SeekStart)
+	must.NoError(t, err)
+
+	datRead, err = Fallout2(osFile)
+	must.NoError(t, err)
+	must.EqOp(t, len(datUpdate.GetDirs()), len(datRead.GetDirs()))
+
+	CheckWritten(t, osFile, datRead, []WriterFile{
+		files[0],
+		WriterFileBytes("TEXT/ENGLISH/GAME/MISC.MSG", []byte("{100}{}{Fallout 2}")),
+		WriterFileBytes("DATA/ADDED.TXT", bytes.Repeat([]byte("added"), 100)),
+	})
+
+	// unchanged files are not moved
+	for _, dir := range datRead.GetDirs() {
+		for _, file := range dir.GetFiles() {
+			if file.GetPath() == "ART/CRITTERS/HAPOWERA.FRM" {
+				test.EqOp(t, offsets[file.GetPath()], file.GetOffset())
+			}
+		}
+	}
+
+	t.Run("RemoveOnly", func(t *testing.T) {
+		var osFile, dat = updateTest(t, files)
+
+		var info, err = osFile.Stat()
+		must.NoError(t, err)
+		var sizeBefore = info.Size()
+
+		_, err = UpdateFallout2(osFile, dat, nil, []string{"DATA/REMOVE.TXT", "ART/CRITTERS/HAPOWERA.FRM"}, WriterOptions{})
+		must.NoError(t, err)
+
+		info, err = osFile.Stat()
+		must.NoError(t, err)
+		test.Less(t, sizeBefore, info.Size())
+
+		_, err = osFile.Seek(0, io.SeekStart)
+		must.NoError(t, err)
+
+		dat, err = Fallout2(osFile)
+		must.NoError(t, err)
+
+		CheckWritten(t, osFile, dat, files[2:])
+	})
+}
+
+func TestUpdateFallout2Invalid(t *testing.T) {
+	var files = []WriterFile{
+		WriterFileBytes("DATA/FILE.TXT", []byte("file")),
+	}
+
+	for name, data := range map[string]struct {
+		files  []WriterFile
+		remove []string
+	}{
+		"Nothing":       {nil, nil},
+		"RemoveMissing": {nil, []string{"DATA/MISSING.TXT"}},
+		"AddRemove":     {[]WriterFile{WriterFileBytes("data/file.txt", nil)}, []string{"DATA/FILE.TXT"}},
+		"PathInvalid":   {[]WriterFile{WriterFileBytes("../FILE.TXT", nil)}, nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var osFile, dat = updateTest(t, files)
+
+			var _, err = UpdateFallout2(osFile, dat, data.files, data.remove, WriterOptions{})
+			test.Error(t, err)
+		})
+	}
+
+	t.Run("OpenError", func(t *testing.T) {
+		var osFile, dat = updateTest(t, files)
+
+		var before, err = os.ReadFile(osFile.Name())
+		must.NoError(t, err)
+
+		_, err = UpdateFallout2(osFile, dat, []WriterFile{
+			WriterFileBytes("DATA/ADDED.TXT", bytes.Repeat([]byte("added"), 100)),
+			{Path: "DATA/BROKEN.TXT", Open: func() (io.ReadCloser, error) { return nil, errors.New("broken") }},
+		}, nil, WriterOptions{})
+		test.Error(t, err)
+
+		// .dat file is not modified
+		var after []byte
+		after, err = os.ReadFile(osFile.Name())
+		must.NoError(t, err)
+		test.Eq(t, before, after)
+
+		_, err = osFile.Seek(0, io.SeekStart)
+		must.NoError(t, err)
+
+		dat, err = Fallout2(osFile)
+		must.NoError(t, err)
+
+		CheckWritten(t, osFile, dat, files)
+	})
+
+	t.Run("SizeLimit", func(t *testing.T) {
+		var osFile, dat = updateTest(t, files)
+
+		var before, err = os.ReadFile(osFile.Name())
+		must.NoError(t, err)
+
+		// Pretend that tree is placed near DAT2 size limit; new content still fits, but new tree does not
+		var datV2 = dat.(*falloutDatV2)
+		datV2.SizeDat = math.MaxUint32 - 10
+
+		_, err = UpdateFallout2(osFile, dat, []WriterFile{WriterFileBytes("DATA/ADDED.TXT", []byte("added"))}, nil, WriterOptions{})
+		test.ErrorContains(t, err, "size limit")
+
+		var after []byte
+		after, err = os.ReadFile(osFile.Name())
+		must.NoError(t, err)
+		test.Eq(t, before, after)
+	})
+
+	t.Run("CaseDuplicates", func(t *testing.T) {
+		var osFile, _ = updateTest(t, []WriterFile{
+			WriterFileBytes("DATA/FILE1.TXT", []byte("file1")),
+			WriterFileBytes("DATA/FILE2.TXT", []byte("file2")),
+		})
+
+		// DAT writer refuses to create such files, tree needs to be patched manually
+		var before, err = os.ReadFile(osFile.Name())
+		must.NoError(t, err)
+
+		before = bytes.Replace(before, []byte(`DATA\FILE2.TXT`), []byte(`DATA\file1.txt`), 1)
+		_, err = osFile.WriteAt(before, 0)
+		must.NoError(t, err)
+
+		var dat FalloutDat
+		dat, err = Fallout2(osFile)
+		must.NoError(t, err)
+
+		for _, remove := range [][]string{nil, {"DATA/FILE1.TXT"}} {
+			_, err = UpdateFallout2(osFile, dat, []WriterFile{WriterFileBytes("DATA/ADDED.TXT", []byte("added"))}, remove, WriterOptions{})
+			test.ErrorContains(t, err, "differ only in case")
+		}
+
+		var after []byte
+		after, err = os.ReadFile(osFile.Name())
+		must.NoError(t, err)
+		test.Eq(t, before, after)
+	})
+
+	t.Run("DAT1", func(t *testing.T) {
+		var osFile, _ = updateTest(t, files)
+
+		var _, err = UpdateFallout2(osFile, new(falloutDatV1), files, nil, WriterOptions{})
+		test.Error(t, err)
+	})
+}
@@ -0,0 +1,217 @@
+package dat
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Compress decides how files data is stored when creating .dat file
+type Compress uint8
+
+const (
+	CompressAuto   Compress = iota // compressed data is used only if it's smaller than original data
+	CompressAlways                 // all files are compressed, except empty ones in DAT1
+	CompressNever                  // all files are stored as-is
+)
+
+// WriterOptions controls creation of .dat files
+type WriterOptions struct {
+	Compress Compress
+
+	// zlib compression level, used by DAT2 only
+	//
+	// If set to 0, `zlib.BestCompression` is used, same as in original .dat files
+	CompressLevel int
+
+	// Minimal compression ratio (real size / packed size), used by `CompressAuto` only
+	//
+	// If set to 1 or lower, compressed data is used whenever it's smaller than original data
+	MinRatio float64
+}
+
+// WriterFile describes a single file which is going to be added to .dat file
+type WriterFile struct {
+	// Path inside .dat file; both `/` and `\` can be used as separator
+	Path string
+
+	// Open returns file content; reader is closed as soon as content has been consumed
+	Open func() (io.ReadCloser, error)
+}
+
+// writerEntry is a `WriterFile` with validated path
+type writerEntry struct {
+	WriterFile
+
+	dir  string // cleaned up *nix format, "." for files without directory
+	name string
+}
+
+// WriterFileBytes returns `WriterFile` using in-memory content
+func WriterFileBytes(filePath string, data []byte) WriterFile {
+	return WriterFile{
+		Path: filePath,
+		Open: func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		},
+	}
+}
+
+// WriterFilesFS returns `WriterFile` for each regular file found in `fsys`
+//
+// Files paths inside .dat file are the same as in `fsys`; use `fs.Sub()` to change root directory
+func WriterFilesFS(fsys fs.FS) (files []WriterFile, err error) {
+	files = make([]WriterFile, 0)
+
+	err = fs.WalkDir(fsys, ".", func(filePath string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !dirEntry.Type().IsRegular() {
+			return nil
+		}
+
+		files = append(files, WriterFile{
+			Path: filePath,
+			Open: func() (io.ReadCloser, error) {
+				return fsys.Open(filePath)
+			},
+		})
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// WriterFilesDat returns `WriterFile` for each file in `dat`, which allows to create new .dat file
+// with same content
+//
+// `stream` must be the same stream which was used to read `dat`, and must stay open until files are written.
+// As .dat file cannot contain entries which differ by case only (see `writerPrepare()`), only first
+// of such entries is used; paths of all skipped entries are returned as `duplicates`
+func WriterFilesDat(stream io.ReaderAt, dat FalloutDat) (files []WriterFile, duplicates []string) {
+	var seen = make(map[string]bool)
+
+	files = make([]WriterFile, 0)
+
+	for _, dir := range dat.GetDirs() {
+		for _, file := range dir.GetFiles() {
+			var pathUpper = strings.ToUpper(path.Clean(strings.ReplaceAll(file.GetPath(), `\`, "/")))
+			if seen[pathUpper] {
+				duplicates = append(duplicates, file.GetPath())
+				continue
+			}
+
+			seen[pathUpper] = true
+			files = append(files, WriterFile{
+				Path: file.GetPath(),
+				Open: func() (io.ReadCloser, error) {
+					return file.Open(stream)
+				},
+			})
+		}
+	}
+
+	return files, duplicates
+}
+
+// readAll returns file content
+func (file WriterFile) readAll() (data []byte, err error) {
+	if file.Open == nil {
+		return nil, fmt.Errorf("%s nil Open", file.Path)
+	}
+
+	var reader io.ReadCloser
+	if reader, err = file.Open(); err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// writerPrepare validates paths of all files, and returns them sorted case-insensitively
+func writerPrepare(files []WriterFile) (entries []writerEntry, err error) {
+	if len(files) < 1 {
+		return nil, fmt.Errorf("list of files is empty")
+	}
+
+	var (
+		seen = make(map[string]string, len(files))
+	)
+
+	entries = make([]writerEntry, 0, len(files))
+
+	for _, file := range files {
+		var filePath = strings.ReplaceAll(file.Path, `\`, "/")
+
+		if filePath == "" || strings.HasPrefix(filePath, "/") {
+			return nil, fmt.Errorf("invalid file path '%s'", file.Path)
+		}
+
+		filePath = path.Clean(filePath)
+		if filePath == "." || filePath == ".." || strings.HasPrefix(filePath, "../") {
+			return nil, fmt.Errorf("invalid file path '%s'", file.Path)
+		}
+
+		// Engine lookup is case-insensitive, so .dat file cannot contain two entries
+		// which differ by case only
+		var pathUpper = strings.ToUpper(filePath)
+		if other, ok := seen[pathUpper]; ok {
+			return nil, fmt.Errorf("duplicated file path '%s' ('%s')", file.Path, other)
+		}
+		seen[pathUpper] = file.Path
+
+		var entry = writerEntry{WriterFile: file}
+		entry.Path = filePath
+		entry.dir, entry.name = path.Dir(filePath), path.Base(filePath)
+
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(a writerEntry, b writerEntry) int {
+		return strings.Compare(strings.ToLower(a.Path), strings.ToLower(b.Path))
+	})
+
+	return entries, nil
+}
+
+// usePacked returns true if compressed data should be stored in .dat file
+func (options WriterOptions) usePacked(sizeReal int, sizePacked int) bool {
+	switch options.Compress {
+	case CompressAlways:
+		return true
+	case CompressNever:
+		return false
+	}
+
+	if sizePacked >= sizeReal {
+		return false
+	}
+
+	return options.MinRatio <= 1 || float64(sizeReal) >= float64(sizePacked)*options.MinRatio
+}
+
+func (options WriterOptions) validate() error {
+	if options.MinRatio < 0 || math.IsNaN(options.MinRatio) || math.IsInf(options.MinRatio, 0) {
+		return fmt.Errorf("invalid MinRatio value (%f)", options.MinRatio)
+	}
+
+	switch options.Compress {
+	case CompressAuto, CompressAlways, CompressNever:
+		return nil
+	}
+
+	return fmt.Errorf("unknown Compress value (%d)", options.Compress)
+}
@@ -0,0 +1,395 @@
+package dat
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"math/rand"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/wipe2238/fo/compress/lzss"
+)
+
+// WriterFilesExtracted returns `WriterFile` for each `Real` file found in `testdata/extracted/fallout<game>`
+func WriterFilesExtracted(tb testing.TB, game uint8) (files []WriterFile) {
+	tb.Helper()
+
+	var dir = filepath.Join("testdata", "extracted", fmt.Sprintf("fallout%d", game))
+
+	var err = filepath.WalkDir(dir, func(filename string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if dirEntry.IsDir() || dirEntry.Name() != "Real" {
+			return nil
+		}
+
+		var data []byte
+		if data, err = os.ReadFile(filename); err != nil {
+			return err
+		}
+
+		var filePath, _ = filepath.Rel(dir, filepath.Dir(filename))
+		files = append(files, WriterFileBytes(strings.ToUpper(filepath.ToSlash(filePath)), data))
+
+		return nil
+	})
+	must.NoError(tb, err)
+	must.SliceNotEmpty(tb, files)
+
+	return files
+}
+
+// CheckWritten compares content of .dat file with files used to create it
+func CheckWritten(tb testing.TB, stream io.ReadSeeker, dat FalloutDat, files []WriterFile) {
+	tb.Helper()
+
+	var datFiles = make(map[string]FalloutFile)
+	for _, dir := range dat.GetDirs() {
+		for _, file := range dir.GetFiles() {
+			datFiles[strings.ToUpper(path.Clean(file.GetPath()))] = file
+		}
+	}
+
+	must.MapLen(tb, len(files), datFiles)
+
+	for _, file := range files {
+		var datFile, ok = datFiles[strings.ToUpper(path.Clean(strings.ReplaceAll(file.Path, `\`, "/")))]
+		must.True(tb, ok, must.Sprintf("file not found: %s", file.Path))
+
+		var bytesFile, bytesDat []byte
+		var err error
+
+		bytesFile, err = file.readAll()
+		must.NoError(tb, err)
+
+		bytesDat, err = datFile.GetBytesReal(stream)
+		must.NoError(tb, err)
+
+		must.EqOp(tb, int64(len(bytesFile)), datFile.GetSizeReal())
+		must.SliceEqFunc(tb, bytesFile, bytesDat, func(a, b byte) bool { return a == b })
+
+		CheckOpen(tb, stream, datFile)
+		CheckBytesAt(tb, stream, datFile)
+	}
+}
+
+func TestWriteFallout1(t *testing.T) {
+	var files = WriterFilesExtracted(t, 1)
+	files = append(files,
+		WriterFileBytes(`TEXT\ENGLISH\EMPTY.MSG`, []byte{}),
+		WriterFileBytes("Data/MixedCase.txt", []byte("Mixed case")),
+		WriterFileBytes("data/LowerCase.txt", []byte("Lower case")),
+		WriterFileBytes("COLOR.PAL", bytes.Repeat([]byte("Fallout "), 1024*5)),
+	)
+
+	for _, compress := range []Compress{CompressAuto, CompressAlways, CompressNever} {
+		t.Run(fmt.Sprintf("Compress%d", compress), func(t *testing.T) {
+			var (
+				err     error
+				osFile  *os.File
+				dat     FalloutDat
+				datRead FalloutDat
+			)
+
+			osFile, err = os.Create(filepath.Join(t.TempDir(), "test.dat"))
+			must.NoError(t, err)
+			defer osFile.Close()
+
+			// make sure that offsets are not affected by stream position
+			_, err = osFile.Write([]byte("DAT1"))
+			must.NoError(t, err)
+
+			dat, err = WriteFallout1(osFile, files, WriterOptions{Compress: compress})
+			must.NoError(t, err)
+			must.NotNil(t, dat)
+
+			var stream = io.NewSectionReader(osFile, 4, (1 << 31))
+
+			datRead, err = Fallout1(stream)
+			must.NoError(t, err)
+			must.EqOp(t, len(dat.GetDirs()), len(datRead.GetDirs()))
+
+			for idx, dir := range datRead.GetDirs() {
+				test.EqOp(t, dat.GetDirs()[idx].GetPath(), dir.GetPath())
+
+				for _, file := range dir.GetFiles() {
+					if file.GetSizeReal() == 0 {
+						test.False(t, file.GetPacked())
+						continue
+					}
+
+					switch compress {
+					case CompressAlways:
+						test.EqOp(t, lzss.FalloutCompressLZSS, file.GetPackedMode())
+					case CompressNever:
+						test.EqOp(t, lzss.FalloutCompressNone, file.GetPackedMode())
+					default:
+						if file.GetPacked() {
+							test.Less(t, file.GetSizeReal(), file.GetSizePacked())
+						}
+					}
+				}
+			}
+
+			CheckWritten(t, stream, datRead, files)
+		})
+	}
+}
+
+func TestWriteFallout2(t *testing.T) {
+	var files = WriterFilesExtracted(t, 2)
+	files = append(files,
+		WriterFileBytes(`TEXT\ENGLISH\EMPTY.MSG`, []byte{}),
+		WriterFileBytes("Data/MixedCase.txt", []byte("Mixed case")),
+		WriterFileBytes("ROOT.TXT", bytes.Repeat([]byte("Fallout "), 1024)),
+	)
+
+	for _, compress := range []Compress{CompressAuto, CompressAlways, CompressNever} {
+		t.Run(fmt.Sprintf("Compress%d", compress), func(t *testing.T) {
+			var (
+				err     error
+				buff    bytes.Buffer
+				dat     FalloutDat
+				datRead FalloutDat
+			)
+
+			dat, err = WriteFallout2(&buff, files, WriterOptions{Compress: compress})
+			must.NoError(t, err)
+			must.NotNil(t, dat)
+
+			datRead, err = Fallout2(bytes.NewReader(buff.Bytes()))
+			must.NoError(t, err)
+			must.EqOp(t, len(dat.GetDirs()), len(datRead.GetDirs()))
+
+			for idx, dir := range datRead.GetDirs() {
+				test.EqOp(t, dat.GetDirs()[idx].GetPath(), dir.GetPath())
+
+				for _, file := range dir.GetFiles() {
+					switch compress {
+					case CompressAlways:
+						test.True(t, file.GetPacked())
+					case CompressNever:
+						test.False(t, file.GetPacked())
+						test.EqOp(t, file.GetSizeReal(), file.GetSizePacked())
+					default:
+						if file.GetPacked() {
+							test.Less(t, file.GetSizeReal(), file.GetSizePacked())
+						}
+					}
+				}
+			}
+
+			CheckWritten(t, bytes.NewReader(buff.Bytes()), datRead, files)
+		})
+	}
+}
+
+func TestWriteFallout2FS(t *testing.T) {
+	var fsys = fstest.MapFS{
+		"art/intrface/iface.frm":     &fstest.MapFile{Data: []byte("iface")},
+		"text/english/game/misc.msg": &fstest.MapFile{Data: []byte("{100}{}{Fallout}")},
+		"data/empty":                 &fstest.MapFile{},
+	}
+
+	var (
+		err   error
+		buff  bytes.Buffer
+		files []WriterFile
+		dat   FalloutDat
+	)
+
+	files, err = WriterFilesFS(fsys)
+	must.NoError(t, err)
+	must.SliceLen(t, 3, files)
+
+	_, err = WriteFallout2(&buff, files, WriterOptions{})
+	must.NoError(t, err)
+
+	dat, err = Fallout2(bytes.NewReader(buff.Bytes()))
+	must.NoError(t, err)
+
+	CheckWritten(t, bytes.NewReader(buff.Bytes()), dat, files)
+}
+
+func TestWriteMinRatio(t *testing.T) {
+	// ~2:1 ratio
+	var half = make([]byte, 4096)
+	rand.New(rand.NewSource(1207)).Read(half[:2048])
+
+	var files = []WriterFile{
+		WriterFileBytes("HALF.DAT", half),
+		WriterFileBytes("TEXT.TXT", bytes.Repeat([]byte("Fallout "), 1024)),
+	}
+
+	for minRatio, expected := range map[float64][]bool{
+		0:   {true, true},
+		1.5: {true, true},
+		3:   {false, true},
+		500: {false, false},
+	} {
+		t.Run(fmt.Sprint(minRatio), func(t *testing.T) {
+			for game, write := range map[uint8]func(*os.File) (FalloutDat, error){
+				1: func(osFile *os.File) (FalloutDat, error) {
+					return WriteFallout1(osFile, files, WriterOptions{MinRatio: minRatio})
+				},
+				2: func(osFile *os.File) (FalloutDat, error) {
+					return WriteFallout2(osFile, files, WriterOptions{MinRatio: minRatio})
+				},
+			} {
+				var osFile, err = os.Create(filepath.Join(t.TempDir(), fmt.Sprintf("test.dat%d", game)))
+				must.NoError(t, err)
+				defer osFile.Close()
+
+				var dat FalloutDat
+				dat, err = write(osFile)
+				must.NoError(t, err)
+
+				var packed []bool
+				for _, dir := range dat.GetDirs() {
+					for _, file := range dir.GetFiles() {
+						packed = append(packed, file.GetPacked())
+					}
+				}
+
+				test.Eq(t, expected, packed, test.Sprintf("DAT%d", game))
+			}
+		})
+	}
+
+	for _, minRatio := range []float64{-1, math.NaN(), math.Inf(1)} {
+		var buff bytes.Buffer
+
+		var _, err = WriteFallout2(&buff, files, WriterOptions{MinRatio: minRatio})
+		test.Error(t, err)
+	}
+}
+
+func TestConvertFallout1(t *testing.T) {
+	var files = WriterFilesExtracted(t, 1)
+	files = append(files,
+		WriterFileBytes("Data/MixedCase.txt", bytes.Repeat([]byte("Mixed case"), 100)),
+		WriterFileBytes("ROOT.TXT", []byte("root")),
+		WriterFileBytes(`TEXT\ENGLISH\EMPTY.MSG`, []byte{}),
+	)
+
+	var (
+		err     error
+		osFile  *os.File
+		dat1    FalloutDat
+		dat2    FalloutDat
+		datRead FalloutDat
+		buff    bytes.Buffer
+	)
+
+	osFile, err = os.Create(filepath.Join(t.TempDir(), "test.dat"))
+	must.NoError(t, err)
+	defer osFile.Close()
+
+	_, err = WriteFallout1(osFile, files, WriterOptions{Compress: CompressAlways})
+	must.NoError(t, err)
+
+	_, err = osFile.Seek(0, io.SeekStart)
+	must.NoError(t, err)
+
+	dat1, err = Fallout1(osFile)
+	must.NoError(t, err)
+
+	dat2, err = ConvertFallout1(osFile, dat1, &buff, WriterOptions{})
+	must.NoError(t, err)
+	must.EqOp(t, 2, dat2.GetGame())
+
+	datRead, err = Fallout2(bytes.NewReader(buff.Bytes()))
+	must.NoError(t, err)
+
+	CheckWritten(t, bytes.NewReader(buff.Bytes()), datRead, files)
+
+	var paths = make(map[string]bool)
+	for _, dir := range datRead.GetDirs() {
+		for _, file := range dir.GetFiles() {
+			paths[file.GetPath()] = file.GetPacked()
+		}
+	}
+
+	test.MapContainsKey(t, paths, "Data/MixedCase.txt")
+	test.MapContainsKey(t, paths, "./ROOT.TXT")
+	test.True(t, paths["Data/MixedCase.txt"])
+
+	// DAT2 cannot be converted
+	_, err = ConvertFallout1(bytes.NewReader(buff.Bytes()), datRead, io.Discard, WriterOptions{})
+	test.Error(t, err)
+}
+
+func TestWriterFilesDat(t *testing.T) {
+	var buff bytes.Buffer
+
+	var _, err = WriteFallout2(&buff, []WriterFile{
+		WriterFileBytes("DATA/FILE1.TXT", []byte("file1")),
+		WriterFileBytes("DATA/FILE2.TXT", []byte("file2")),
+		WriterFileBytes("ROOT.TXT", []byte("root")),
+	}, WriterOptions{})
+	must.NoError(t, err)
+
+	// DAT writer refuses to create such files, tree needs to be patched manually
+	var data = bytes.Replace(buff.Bytes(), []byte(`DATA\FILE2.TXT`), []byte(`DATA\file1.txt`), 1)
+
+	var dat FalloutDat
+	dat, err = Fallout2(bytes.NewReader(data))
+	must.NoError(t, err)
+
+	var files, duplicates = WriterFilesDat(bytes.NewReader(data), dat)
+	test.Eq(t, []string{"DATA/file1.txt"}, duplicates)
+	must.SliceLen(t, 2, files)
+	test.SliceContainsFunc(t, files, "DATA/FILE1.TXT", func(file WriterFile, path string) bool { return file.Path == path })
+
+	buff.Reset()
+	_, err = WriteFallout2(&buff, files, WriterOptions{})
+	must.NoError(t, err)
+
+	dat, err = Fallout2(bytes.NewReader(buff.Bytes()))
+	must.NoError(t, err)
+
+	CheckWritten(t, bytes.NewReader(buff.Bytes()), dat, []WriterFile{
+		WriterFileBytes("DATA/FILE1.TXT", []byte("file1")),
+		WriterFileBytes("ROOT.TXT", []byte("root")),
+	})
+}
+
+func TestWriteInvalid(t *testing.T) {
+	var data = []byte("data")
+
+	for name, files := range map[string][]WriterFile{
+		"Empty":     {},
+		"PathEmpty": {WriterFileBytes("", data)},
+		"PathRoot":  {WriterFileBytes("/ROOT.TXT", data)},
+		"PathDot":   {WriterFileBytes(".", data)},
+		"PathUp":    {WriterFileBytes(`..\ROOT.TXT`, data)},
+		"PathDup":   {WriterFileBytes("data/file.txt", data), WriterFileBytes(`DATA\FILE.TXT`, data)},
+		"OpenNil":   {{Path: "ROOT.TXT"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buff bytes.Buffer
+
+			var _, err = WriteFallout2(&buff, files, WriterOptions{})
+			test.Error(t, err)
+		})
+	}
+
+	t.Run("Compress", func(t *testing.T) {
+		var buff bytes.Buffer
+
+		var _, err = WriteFallout2(&buff, []WriterFile{WriterFileBytes("ROOT.TXT", data)}, WriterOptions{Compress: 3})
+		test.Error(t, err)
+	})
+}
@@ -0,0 +1,220 @@
+package discover
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wipe2238/fo/steam"
+)
+
+// candidate is a directory which might contain game installation
+type candidate struct {
+	source Source
+	path   string
+}
+
+// installDirs returns well-known install directories, relative to drive root (Wine) or home directory
+func installDirs() (dirs []string) {
+	var (
+		parents = []string{
+			"",
+			"Games",
+			"GOG Games",
+			"GOG Galaxy/Games",
+			"Games/GOG",
+			"Games/Heroic",
+			"Program Files/GOG Galaxy/Games",
+			"Program Files (x86)/GOG Galaxy/Games",
+			"Program Files/GOG.com",
+			"Program Files (x86)/GOG.com",
+			"Program Files/BlackIsle",
+			"Program Files (x86)/BlackIsle",
+			"Program Files/Interplay",
+			"Program Files (x86)/Interplay",
+		}
+		names = []string{"Fallout", "Fallout 2", "Fallout2", "FALLOUT", "FALLOUT2"}
+	)
+
+	for _, parent := range parents {
+		for _, name := range names {
+			dirs = append(dirs, filepath.Join(filepath.FromSlash(parent), name))
+		}
+	}
+
+	return dirs
+}
+
+// candidates returns all directories which should be validated by `Find()`
+func candidates() (result []candidate) {
+	for _, appID := range []uint64{steam.AppID.Fallout1, steam.AppID.Fallout2} {
+		if path, err := steam.GetAppPath(appID); err == nil {
+			result = append(result, candidate{SourceSteam, path})
+		}
+	}
+
+	var home, err = os.UserHomeDir()
+	if err != nil {
+		return result
+	}
+
+	var configDir string
+	if configDir, err = os.UserConfigDir(); err != nil {
+		configDir = filepath.Join(home, ".config")
+	}
+
+	var dataDir = os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		dataDir = filepath.Join(home, ".local", "share")
+	}
+
+	//
+	// Heroic
+	//
+
+	for _, heroicDir := range []string{
+		filepath.Join(configDir, "heroic"),
+		filepath.Join(home, ".var", "app", "com.heroicgameslauncher.hgl", "config", "heroic"), // Flatpak
+	} {
+		for _, path := range heroicInstalled(filepath.Join(heroicDir, "gog_store", "installed.json")) {
+			result = append(result, candidate{SourceHeroic, path})
+		}
+	}
+
+	//
+	// Lutris
+	//
+
+	var winePrefixes []string
+
+	for _, lutrisDir := range []string{
+		filepath.Join(configDir, "lutris", "games"),
+		filepath.Join(dataDir, "lutris", "games"),
+	} {
+		var matches, _ = filepath.Glob(filepath.Join(lutrisDir, "*.yml"))
+
+		for _, filename := range matches {
+			var paths, prefix = lutrisGame(filename)
+
+			for _, path := range paths {
+				result = append(result, candidate{SourceLutris, path})
+			}
+
+			if prefix != "" {
+				winePrefixes = append(winePrefixes, prefix)
+			}
+		}
+	}
+
+	//
+	// Wine
+	//
+
+	if prefix := os.Getenv("WINEPREFIX"); prefix != "" {
+		winePrefixes = append(winePrefixes, prefix)
+	}
+
+	winePrefixes = append(winePrefixes, filepath.Join(home, ".wine"))
+
+	for _, prefix := range winePrefixes {
+		for _, dir := range installDirs() {
+			result = append(result, candidate{SourceWine, filepath.Join(prefix, "drive_c", dir)})
+		}
+	}
+
+	//
+	// Well-known directories
+	//
+
+	for _, dir := range installDirs() {
+		if filepath.Dir(dir) == "." {
+			// too generic for home directory
+			continue
+		}
+
+		result = append(result, candidate{SourceDir, filepath.Join(home, dir)})
+	}
+
+	return result
+}
+
+// heroicInstalled returns install paths of all GOG games managed by Heroic
+func heroicInstalled(filename string) (paths []string) {
+	var data, err = os.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+
+	var installed struct {
+		Installed []struct {
+			InstallPath string `json:"install_path"`
+		} `json:"installed"`
+	}
+
+	if err = json.Unmarshal(data, &installed); err != nil {
+		return nil
+	}
+
+	for _, game := range installed.Installed {
+		if game.InstallPath != "" {
+			paths = append(paths, game.InstallPath)
+		}
+	}
+
+	return paths
+}
+
+// lutrisGame returns possible game directories, and Wine prefix, found in Lutris game configuration
+//
+// Only a few keys from `game` section are used, so configuration is parsed line by line,
+// without full YAML support
+func lutrisGame(filename string) (paths []string, prefix string) {
+	var osFile, err = os.Open(filename)
+	if err != nil {
+		return nil, ""
+	}
+	defer osFile.Close()
+
+	var (
+		scanner = bufio.NewScanner(osFile)
+		section string
+	)
+
+	for scanner.Scan() {
+		var line = scanner.Text()
+
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		var key, value, ok = strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			section = key
+			continue
+		} else if section != "game" {
+			continue
+		}
+
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if !filepath.IsAbs(value) {
+			continue
+		}
+
+		switch key {
+		case "exe":
+			paths = append(paths, filepath.Dir(value))
+		case "working_dir":
+			paths = append(paths, value)
+		case "prefix":
+			prefix = value
+		}
+	}
+
+	return paths, prefix
+}
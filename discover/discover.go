@@ -0,0 +1,190 @@
+// Package discover locates installed games
+//
+// Games are searched in Steam libraries, GOG installations managed by Heroic or Lutris,
+// Wine prefixes, and a number of well-known install directories. Every candidate directory
+// is validated by presence of master.dat and critter.dat
+package discover
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/wipe2238/fo/dat"
+)
+
+const errPackage = "fo/discover:"
+
+// Source describes where game installation has been found
+type Source string
+
+const (
+	SourceSteam  Source = "steam"
+	SourceHeroic Source = "heroic"
+	SourceLutris Source = "lutris"
+	SourceWine   Source = "wine"
+	SourceDir    Source = "dir"
+)
+
+// Install describes a single game installation
+type Install struct {
+	Game   uint8  // 1 or 2
+	Source Source // where installation has been found
+	Path   string // game directory, containing master.dat and critter.dat
+}
+
+// Find returns all installations of given game (1 or 2), or all games if `game` is 0
+//
+// Each directory is reported once, using first source it has been found in;
+// sources are searched in order: Steam, Heroic, Lutris, Wine, well-known directories
+func Find(game uint8) (installs []Install, err error) {
+	const errPrefix = errPackage + " Find()"
+
+	if game > 2 {
+		return nil, fmt.Errorf("%s invalid game %d", errPrefix, game)
+	}
+
+	var seen = make(map[string]bool)
+
+	for _, candidate := range candidates() {
+		var install Install
+		if install, err = Dir(candidate.path); err != nil {
+			continue
+		}
+
+		if game != 0 && install.Game != game {
+			continue
+		}
+
+		var key = install.Path
+		if realPath, err := filepath.EvalSymlinks(key); err == nil {
+			key = realPath
+		}
+
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		install.Source = candidate.source
+		installs = append(installs, install)
+	}
+
+	if len(installs) == 0 {
+		if game == 0 {
+			return nil, fmt.Errorf("%s cannot find any game", errPrefix)
+		}
+
+		return nil, fmt.Errorf("%s cannot find Fallout %d", errPrefix, game)
+	}
+
+	return installs, nil
+}
+
+// Dir validates game installation in given directory
+//
+// Game version is detected by configuration file name (fallout.cfg or fallout2.cfg);
+// if configuration file is missing, master.dat format is used instead
+func Dir(path string) (install Install, err error) {
+	const errPrefix = errPackage + " Dir()"
+
+	install = Install{Source: SourceDir, Path: filepath.Clean(path)}
+
+	var entries []os.DirEntry
+	if entries, err = os.ReadDir(install.Path); err != nil {
+		return Install{}, fmt.Errorf("%s %w", errPrefix, err)
+	}
+
+	var find = func(name string) (string, bool) {
+		var idx = slices.IndexFunc(entries, func(entry os.DirEntry) bool {
+			return strings.EqualFold(entry.Name(), name) && entry.Type().IsRegular()
+		})
+
+		if idx < 0 {
+			return "", false
+		}
+
+		return filepath.Join(install.Path, entries[idx].Name()), true
+	}
+
+	var masterDat, ok = find("master.dat")
+	if !ok {
+		return Install{}, fmt.Errorf("%s cannot find master.dat in '%s'", errPrefix, install.Path)
+	} else if _, ok = find("critter.dat"); !ok {
+		return Install{}, fmt.Errorf("%s cannot find critter.dat in '%s'", errPrefix, install.Path)
+	}
+
+	if _, ok = find("fallout2.cfg"); ok {
+		install.Game = 2
+	} else if _, ok = find("fallout.cfg"); ok {
+		install.Game = 1
+	} else {
+		var osFile *os.File
+
+		if osFile, err = os.Open(masterDat); err != nil {
+			return Install{}, fmt.Errorf("%s %w", errPrefix, err)
+		}
+		defer osFile.Close()
+
+		// same order as `dat.Open()`
+		for idx, reader := range [2]func(io.ReadSeeker) (dat.FalloutDat, error){dat.Fallout1, dat.Fallout2} {
+			if _, err = osFile.Seek(0, io.SeekStart); err != nil {
+				return Install{}, fmt.Errorf("%s %w", errPrefix, err)
+			}
+
+			if _, err = reader(osFile); err == nil {
+				install.Game = uint8(idx + 1)
+				break
+			}
+		}
+
+		if install.Game == 0 {
+			return Install{}, fmt.Errorf("%s cannot detect game version in '%s'", errPrefix, install.Path)
+		}
+	}
+
+	return install, nil
+}
+
+// FilePath returns path to file inside game directory; lookup is case-insensitive
+//
+// `filename` must be relative to game directory, and cannot point outside of it
+func (install Install) FilePath(filename string) (path string, err error) {
+	var errPrefix = fmt.Sprintf("%s FilePath(%s)", errPackage, filename)
+
+	var name = filepath.ToSlash(filepath.Clean(filepath.FromSlash(strings.ReplaceAll(filename, `\`, "/"))))
+	if filename == "" || name == "." || filepath.IsAbs(filepath.FromSlash(name)) || strings.HasPrefix(name, "/") ||
+		name == ".." || strings.HasPrefix(name, "../") {
+		return "", fmt.Errorf("%s filename must be relative to game directory", errPrefix)
+	}
+
+	path = install.Path
+
+	for _, elem := range strings.Split(name, "/") {
+		var entries []os.DirEntry
+		if entries, err = os.ReadDir(path); err != nil {
+			return "", fmt.Errorf("%s file not found", errPrefix)
+		}
+
+		var idx = slices.IndexFunc(entries, func(entry os.DirEntry) bool {
+			return entry.Name() == elem
+		})
+
+		if idx < 0 {
+			idx = slices.IndexFunc(entries, func(entry os.DirEntry) bool {
+				return strings.EqualFold(entry.Name(), elem)
+			})
+		}
+
+		if idx < 0 {
+			return "", fmt.Errorf("%s file not found", errPrefix)
+		}
+
+		path = filepath.Join(path, entries[idx].Name())
+	}
+
+	return path, nil
+}
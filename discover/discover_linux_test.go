@@ -0,0 +1,77 @@
+package discover
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/wipe2238/fo/steam"
+)
+
+// SetHome isolates discovery from current user environment
+func SetHome(t *testing.T) (home string) {
+	home = t.TempDir()
+
+	t.Setenv("HOME", home)
+	t.Setenv(steam.EnvInstallPath, home) // not a valid Steam directory
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_DATA_HOME", "WINEPREFIX"} {
+		t.Setenv(env, "")
+	}
+
+	return home
+}
+
+func TestFind(t *testing.T) {
+	var home = SetHome(t)
+
+	var _, err = Find(0)
+	test.Error(t, err)
+
+	_, err = Find(3)
+	test.Error(t, err)
+
+	// Heroic
+	var heroicGame = MakeGame(t, filepath.Join(home, "Games", "Heroic", "Fallout 2"), 2, true)
+	var heroicConfig = filepath.Join(home, ".config", "heroic", "gog_store")
+	must.NoError(t, os.MkdirAll(heroicConfig, 0755))
+	must.NoError(t, os.WriteFile(filepath.Join(heroicConfig, "installed.json"), []byte(fmt.Sprintf(
+		`{"installed":[{"appName":"1440151285","platform":"windows","install_path":%q},{"appName":"1","install_path":%q}]}`,
+		heroicGame, filepath.Join(home, "missing"))), 0644))
+
+	// Lutris, with game directory inside Wine prefix
+	var lutrisPrefix = filepath.Join(home, "Games", "gog", "fallout")
+	var lutrisGame = MakeGame(t, filepath.Join(lutrisPrefix, "drive_c", "GOG Games", "Fallout"), 1, false)
+	var lutrisConfig = filepath.Join(home, ".local", "share", "lutris", "games")
+	must.NoError(t, os.MkdirAll(lutrisConfig, 0755))
+	must.NoError(t, os.WriteFile(filepath.Join(lutrisConfig, "fallout-1234.yml"), []byte(fmt.Sprintf(
+		"game:\n  exe: %s\n  prefix: '%s'\ngame_slug: fallout\nsystem:\n  exe: /usr/bin/false\n",
+		filepath.Join(lutrisGame, "falloutw.exe"), lutrisPrefix)), 0644))
+
+	// Wine
+	var wineGame = MakeGame(t, filepath.Join(home, ".wine", "drive_c", "Program Files (x86)", "GOG Galaxy", "Games", "Fallout 2"), 2, true)
+
+	// Plain directory, and symlink to already known directory
+	var dirGame = MakeGame(t, filepath.Join(home, "GOG Games", "Fallout"), 1, true)
+	must.NoError(t, os.Symlink(heroicGame, filepath.Join(home, "GOG Games", "Fallout 2")))
+
+	var installs []Install
+	installs, err = Find(0)
+	must.NoError(t, err)
+	test.Eq(t, []Install{
+		{Game: 2, Source: SourceHeroic, Path: heroicGame},
+		{Game: 1, Source: SourceLutris, Path: lutrisGame},
+		{Game: 2, Source: SourceWine, Path: wineGame},
+		{Game: 1, Source: SourceDir, Path: dirGame},
+	}, installs)
+
+	installs, err = Find(1)
+	must.NoError(t, err)
+	test.Eq(t, []Install{
+		{Game: 1, Source: SourceLutris, Path: lutrisGame},
+		{Game: 1, Source: SourceDir, Path: dirGame},
+	}, installs)
+}
@@ -0,0 +1,104 @@
+package discover
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/wipe2238/fo/dat"
+)
+
+// MakeGame creates minimal game installation in given directory
+//
+// If `cfg` is false, configuration file is not created, and game can only be detected by master.dat format
+func MakeGame(tb testing.TB, dir string, game uint8, cfg bool) string {
+	tb.Helper()
+
+	must.NoError(tb, os.MkdirAll(dir, 0755))
+
+	for _, name := range []string{"master.dat", "critter.dat"} {
+		var osFile, err = os.Create(filepath.Join(dir, name))
+		must.NoError(tb, err)
+
+		var files = []dat.WriterFile{dat.WriterFileBytes("TEXT/"+name, []byte(name))}
+		if game == 1 {
+			_, err = dat.WriteFallout1(osFile, files, dat.WriterOptions{})
+		} else {
+			_, err = dat.WriteFallout2(osFile, files, dat.WriterOptions{})
+		}
+
+		must.NoError(tb, err)
+		must.NoError(tb, osFile.Close())
+	}
+
+	if cfg {
+		var name = "fallout2.cfg"
+		if game == 1 {
+			name = "fallout.cfg"
+		}
+
+		must.NoError(tb, os.WriteFile(filepath.Join(dir, name), []byte("[system]\n"), 0644))
+	}
+
+	return dir
+}
+
+func TestDir(t *testing.T) {
+	for _, game := range []uint8{1, 2} {
+		for _, cfg := range []bool{true, false} {
+			t.Run(fmt.Sprintf("Fallout%d/%t", game, cfg), func(t *testing.T) {
+				var dir = MakeGame(t, t.TempDir(), game, cfg)
+
+				var install, err = Dir(dir)
+				must.NoError(t, err)
+				test.Eq(t, Install{Game: game, Source: SourceDir, Path: dir}, install)
+			})
+		}
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		var dir = MakeGame(t, t.TempDir(), 2, true)
+		must.NoError(t, os.Remove(filepath.Join(dir, "critter.dat")))
+
+		var _, err = Dir(dir)
+		test.Error(t, err)
+
+		_, err = Dir(filepath.Join(dir, "missing"))
+		test.Error(t, err)
+
+		// unknown format
+		dir = MakeGame(t, t.TempDir(), 2, false)
+		must.NoError(t, os.WriteFile(filepath.Join(dir, "master.dat"), []byte("master.dat"), 0644))
+
+		_, err = Dir(dir)
+		test.Error(t, err)
+	})
+}
+
+func TestFilePath(t *testing.T) {
+	var dir = MakeGame(t, t.TempDir(), 2, true)
+	must.NoError(t, os.MkdirAll(filepath.Join(dir, "data", "Maps"), 0755))
+	must.NoError(t, os.WriteFile(filepath.Join(dir, "data", "Maps", "artemple.map"), nil, 0644))
+
+	var install = Install{Game: 2, Path: dir}
+
+	for filename, expected := range map[string]string{
+		"MASTER.DAT":                     "master.dat",
+		"master.dat":                     "master.dat",
+		`DATA\MAPS\ARTEMPLE.MAP`:         "data/Maps/artemple.map",
+		"data/maps/../MAPS/ArTemple.map": "data/Maps/artemple.map",
+	} {
+		var path, err = install.FilePath(filename)
+		must.NoError(t, err, must.Sprint(filename))
+		test.EqOp(t, filepath.Join(dir, filepath.FromSlash(expected)), path)
+	}
+
+	for _, filename := range []string{"", ".", "..", "../master.dat", "/master.dat", `\master.dat`, "missing.dat", "master.dat/file"} {
+		var _, err = install.FilePath(filename)
+		test.Error(t, err, test.Sprint(filename))
+	}
+}
@@ -5,8 +5,6 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-
-	"github.com/Jleagle/steam-go/steamvdf"
 )
 
 func GetAppPath(appID uint64) (appPath string, err error) {
@@ -20,33 +18,15 @@ func GetAppPath(appID uint64) (appPath string, err error) {
 	}
 
 	for _, libraryPath := range librariesPaths {
-		var (
-			kvTemp steamvdf.KeyValue
-			ok     bool
-		)
-
 		// read <library path>/steamapps/appmanifest_<appID>.acf
 
-		var path = filepath.Join(libraryPath, "steamapps", fmt.Sprintf("appmanifest_%d.acf", appID))
-
-		if _, err = os.Stat(path); err != nil {
-			continue
-		}
-
-		if kvTemp, err = steamvdf.ReadFile(path); err != nil {
-			continue
-		}
-
-		// extract <app installdir> from appmanifest
-
-		if kvTemp, ok = kvTemp.GetChild("installdir"); !ok {
+		var manifest AppManifest
+		if manifest, err = ReadAppManifest(os.DirFS(libraryPath), appID); err != nil {
 			continue
 		}
 
 		// app path = <library path>/steamapps/common/<app installdir>
-		appPath = filepath.Join(filepath.Dir(path), "common", kvTemp.Value)
-		return filepath.Clean(appPath), nil
-
+		return filepath.Clean(manifest.InstallPath(libraryPath)), nil
 	}
 
 	// app path not found
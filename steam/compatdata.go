@@ -0,0 +1,200 @@
+package steam
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProtonUser is a name of Windows user used by Proton
+const ProtonUser = "steamuser"
+
+// KnownFolder identifies per-user directory inside Proton prefix
+type KnownFolder string
+
+const (
+	FolderProfile      KnownFolder = "Profile"      // %USERPROFILE%
+	FolderRoamingData  KnownFolder = "RoamingData"  // %APPDATA%
+	FolderLocalData    KnownFolder = "LocalData"    // %LOCALAPPDATA%
+	FolderDocuments    KnownFolder = "Documents"    // My Documents
+	FolderSavedGames   KnownFolder = "SavedGames"   // Saved Games
+	FolderVirtualStore KnownFolder = "VirtualStore" // %LOCALAPPDATA%\VirtualStore
+)
+
+// knownFolders holds known folders paths relative to %USERPROFILE%; if more than one path is listed,
+// first existing one is used (older Proton versions use Windows XP layout)
+var knownFolders = map[KnownFolder][]string{
+	FolderProfile:      {"."},
+	FolderRoamingData:  {`AppData\Roaming`, "Application Data"},
+	FolderLocalData:    {`AppData\Local`, `Local Settings\Application Data`},
+	FolderDocuments:    {"Documents", "My Documents"},
+	FolderSavedGames:   {"Saved Games"},
+	FolderVirtualStore: {`AppData\Local\VirtualStore`, `Local Settings\Application Data\VirtualStore`},
+}
+
+// Prefix is a Wine prefix created by Proton for a single app, <library path>/steamapps/compatdata/<appID>/pfx
+//
+// Prefix holds everything which app writes outside of its install directory, such as saved games
+// or configuration files; it's created by Steam on first launch, and removed along with app
+type Prefix struct {
+	AppID uint64
+	Path  string
+}
+
+// GetAppPrefix returns Proton prefix of given app, searching all Steam libraries
+//
+// Library where app is installed is checked first
+func GetAppPrefix(appID uint64) (prefix Prefix, err error) {
+	var (
+		self           = fmt.Sprintf("GetAppPrefix(%d)", appID)
+		librariesPaths []string
+	)
+
+	if librariesPaths, err = GetLibrariesPaths(); err != nil {
+		return Prefix{}, err
+	}
+
+	// Steam keeps compatdata in app library, unless it's not possible (e.g. filesystem without symlinks support),
+	// in which case it's kept in <steam path>
+	for _, libraryPath := range librariesPaths {
+		if _, err = ReadAppManifest(os.DirFS(libraryPath), appID); err != nil {
+			continue
+		}
+
+		if prefix, err = ReadAppPrefix(libraryPath, appID); err == nil {
+			return prefix, nil
+		}
+	}
+
+	for _, libraryPath := range librariesPaths {
+		if prefix, err = ReadAppPrefix(libraryPath, appID); err == nil {
+			return prefix, nil
+		}
+	}
+
+	return Prefix{}, fmt.Errorf("%s cannot locate Proton prefix", self)
+}
+
+// ReadAppPrefix returns Proton prefix of given app, stored in given library
+func ReadAppPrefix(libraryPath string, appID uint64) (prefix Prefix, err error) {
+	var self = fmt.Sprintf("ReadAppPrefix(%d)", appID)
+
+	prefix = Prefix{
+		AppID: appID,
+		Path:  filepath.Join(libraryPath, "steamapps", "compatdata", fmt.Sprint(appID), "pfx"),
+	}
+
+	var fileInfo os.FileInfo
+	if fileInfo, err = os.Stat(prefix.DriveC()); err != nil {
+		return Prefix{}, fmt.Errorf("%s %w", self, err)
+	} else if !fileInfo.IsDir() {
+		return Prefix{}, fmt.Errorf("%s not a directory '%s'", self, prefix.DriveC())
+	}
+
+	return prefix, nil
+}
+
+// DriveC returns directory used as C: drive
+func (prefix Prefix) DriveC() string {
+	return filepath.Join(prefix.Path, "drive_c")
+}
+
+// Folder returns host path of given known folder; directory might not exist
+func (prefix Prefix) Folder(folder KnownFolder) (path string, err error) {
+	var candidates, ok = knownFolders[folder]
+	if !ok {
+		return "", fmt.Errorf("Folder(%s) unknown folder", folder)
+	}
+
+	for _, candidate := range candidates {
+		if path, err = prefix.WindowsPath(`C:\users\` + ProtonUser + `\` + candidate); err != nil {
+			return "", err
+		}
+
+		if _, err = os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	return prefix.WindowsPath(`C:\users\` + ProtonUser + `\` + candidates[0])
+}
+
+// WindowsPath converts absolute Windows path, as seen by app running inside prefix, to host path
+//
+// Drive C: is mapped to `DriveC()`, other drives are resolved using prefix dosdevices
+// (by default, Z: is host root directory). Environment variables %USERPROFILE%, %APPDATA%
+// and %LOCALAPPDATA% are expanded; path case is not changed.
+func (prefix Prefix) WindowsPath(windowsPath string) (path string, err error) {
+	var self = fmt.Sprintf("WindowsPath(%s)", windowsPath)
+
+	for variable, folder := range map[string]string{
+		"%USERPROFILE%":  `C:\users\` + ProtonUser,
+		"%APPDATA%":      `C:\users\` + ProtonUser + `\` + knownFolders[FolderRoamingData][0],
+		"%LOCALAPPDATA%": `C:\users\` + ProtonUser + `\` + knownFolders[FolderLocalData][0],
+	} {
+		if len(windowsPath) >= len(variable) && strings.EqualFold(windowsPath[:len(variable)], variable) {
+			windowsPath = folder + windowsPath[len(variable):]
+			break
+		}
+	}
+
+	if len(windowsPath) < 3 || windowsPath[1] != ':' || windowsPath[2] != '\\' {
+		return "", fmt.Errorf("%s not an absolute path", self)
+	}
+
+	var (
+		drive = strings.ToLower(windowsPath[:2])
+		parts []string
+	)
+
+	for _, part := range strings.Split(windowsPath[3:], `\`) {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if len(parts) == 0 {
+				return "", fmt.Errorf("%s path outside of drive", self)
+			}
+
+			parts = parts[:len(parts)-1]
+		default:
+			parts = append(parts, part)
+		}
+	}
+
+	var root string
+	if drive == "c:" {
+		root = prefix.DriveC()
+	} else if root, err = filepath.EvalSymlinks(filepath.Join(prefix.Path, "dosdevices", drive)); err != nil {
+		return "", fmt.Errorf("%s drive %s not mapped", self, strings.ToUpper(drive))
+	}
+
+	return filepath.Join(append([]string{root}, parts...)...), nil
+}
+
+// VirtualStorePath returns host path of file written by app to a protected location, such as C:\Program Files
+//
+// Such writes are redirected by Windows (including Wine) to %LOCALAPPDATA%\VirtualStore; app still sees
+// original path, while file is kept in per-user directory
+func (prefix Prefix) VirtualStorePath(windowsPath string) (path string, err error) {
+	if len(windowsPath) < 3 || !strings.EqualFold(windowsPath[:3], `C:\`) {
+		return "", fmt.Errorf("VirtualStorePath(%s) path must be on C: drive", windowsPath)
+	}
+
+	var virtualStore string
+	if virtualStore, err = prefix.Folder(FolderVirtualStore); err != nil {
+		return "", err
+	}
+
+	if path, err = prefix.WindowsPath(windowsPath); err != nil {
+		return "", err
+	}
+
+	var relPath string
+	if relPath, err = filepath.Rel(prefix.DriveC(), path); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(virtualStore, relPath), nil
+}
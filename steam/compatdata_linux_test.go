@@ -0,0 +1,112 @@
+package steam
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+// MakePrefix creates fake Proton prefix for given app, with Z: drive mapped to `driveZ`
+func MakePrefix(tb testing.TB, libraryPath string, appID uint64, driveZ string, dirs ...string) Prefix {
+	tb.Helper()
+
+	var prefix = Prefix{AppID: appID, Path: filepath.Join(libraryPath, "steamapps", "compatdata", fmt.Sprint(appID), "pfx")}
+
+	must.NoError(tb, os.MkdirAll(filepath.Join(prefix.Path, "dosdevices"), 0755))
+	must.NoError(tb, os.Symlink("../drive_c", filepath.Join(prefix.Path, "dosdevices", "c:")))
+	must.NoError(tb, os.Symlink(driveZ, filepath.Join(prefix.Path, "dosdevices", "z:")))
+
+	for _, dir := range append(dirs, "windows") {
+		must.NoError(tb, os.MkdirAll(filepath.Join(prefix.DriveC(), filepath.FromSlash(dir)), 0755))
+	}
+
+	return prefix
+}
+
+func TestAppPrefix(t *testing.T) {
+	var (
+		steamDir = MakeSteamDir(t, t.TempDir(), map[uint64]string{AppID.Fallout2: "Fallout 2"})
+		driveZ   = t.TempDir()
+		prefix   = MakePrefix(t, steamDir, AppID.Fallout2, driveZ, "users/steamuser/AppData/Local/VirtualStore", "users/steamuser/My Documents")
+	)
+
+	t.Setenv(EnvInstallPath, steamDir)
+
+	var found, err = GetAppPrefix(AppID.Fallout2)
+	must.NoError(t, err)
+	test.Eq(t, prefix, found)
+	test.EqOp(t, filepath.Join(steamDir, "steamapps", "compatdata", "38410", "pfx", "drive_c"), found.DriveC())
+
+	_, err = GetAppPrefix(AppID.Fallout1)
+	test.Error(t, err)
+
+	var userDir = filepath.Join(prefix.DriveC(), "users", ProtonUser)
+
+	t.Run("Folder", func(t *testing.T) {
+		for folder, expected := range map[KnownFolder]string{
+			FolderProfile:      userDir,
+			FolderRoamingData:  filepath.Join(userDir, "AppData", "Roaming"),
+			FolderLocalData:    filepath.Join(userDir, "AppData", "Local"),
+			FolderDocuments:    filepath.Join(userDir, "My Documents"), // older layout, directory exists
+			FolderSavedGames:   filepath.Join(userDir, "Saved Games"),
+			FolderVirtualStore: filepath.Join(userDir, "AppData", "Local", "VirtualStore"),
+		} {
+			var path, err = prefix.Folder(folder)
+			must.NoError(t, err)
+			test.EqOp(t, expected, path, test.Sprint(folder))
+		}
+
+		var _, err = prefix.Folder("Desktop")
+		test.Error(t, err)
+	})
+
+	t.Run("WindowsPath", func(t *testing.T) {
+		for windowsPath, expected := range map[string]string{
+			`C:\`:                                   prefix.DriveC(),
+			`c:\windows\..\Games\Fallout2\SAVEGAME`: filepath.Join(prefix.DriveC(), "Games", "Fallout2", "SAVEGAME"),
+			`%USERPROFILE%\Saved Games`:             filepath.Join(userDir, "Saved Games"),
+			`%appdata%\fallout2.cfg`:                filepath.Join(userDir, "AppData", "Roaming", "fallout2.cfg"),
+			`%LOCALAPPDATA%`:                        filepath.Join(userDir, "AppData", "Local"),
+			`Z:\Fallout 2\fallout2.cfg`:             filepath.Join(driveZ, "Fallout 2", "fallout2.cfg"),
+		} {
+			var path, err = prefix.WindowsPath(windowsPath)
+			must.NoError(t, err, must.Sprint(windowsPath))
+			test.EqOp(t, expected, path, test.Sprint(windowsPath))
+		}
+
+		for _, windowsPath := range []string{``, `C:`, `fallout2.cfg`, `\fallout2.cfg`, `C:\..\fallout2.cfg`, `D:\fallout2.cfg`, `%TEMP%\fallout2.cfg`} {
+			var _, err = prefix.WindowsPath(windowsPath)
+			test.Error(t, err, test.Sprint(windowsPath))
+		}
+	})
+
+	t.Run("VirtualStorePath", func(t *testing.T) {
+		var path, err = prefix.VirtualStorePath(`C:\Program Files (x86)\Fallout 2\fallout2.cfg`)
+		must.NoError(t, err)
+		test.EqOp(t, filepath.Join(userDir, "AppData", "Local", "VirtualStore", "Program Files (x86)", "Fallout 2", "fallout2.cfg"), path)
+
+		_, err = prefix.VirtualStorePath(`Z:\Fallout 2\fallout2.cfg`)
+		test.Error(t, err)
+	})
+
+	t.Run("Libraries", func(t *testing.T) {
+		// prefix in library where app is installed is preferred over one in <steam path>
+		var libraryDir = MakeSteamDir(t, t.TempDir(), map[uint64]string{AppID.Fallout1: "Fallout"})
+		var libraryPrefix = MakePrefix(t, libraryDir, AppID.Fallout1, driveZ)
+		MakePrefix(t, steamDir, AppID.Fallout1, driveZ)
+
+		var vdf, err = os.ReadFile(filepath.Join(steamDir, "steamapps", "libraryfolders.vdf"))
+		must.NoError(t, err)
+		vdf = append(vdf[:len(vdf)-2], []byte("\t\"1\"\n\t{\n\t\t\"path\"\t\t\""+libraryDir+"\"\n\t}\n}\n")...)
+		must.NoError(t, os.WriteFile(filepath.Join(steamDir, "steamapps", "libraryfolders.vdf"), vdf, 0644))
+
+		var found Prefix
+		found, err = GetAppPrefix(AppID.Fallout1)
+		must.NoError(t, err)
+		test.Eq(t, libraryPrefix, found)
+	})
+}
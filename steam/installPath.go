@@ -0,0 +1,33 @@
+package steam
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// EnvInstallPath is a name of environment variable which overrides Steam directory discovery
+//
+// When set to non-empty value, it must point to valid Steam directory; no other locations are checked
+const EnvInstallPath = "FO_STEAM_PATH"
+
+// installPathEnv returns Steam directory set by `EnvInstallPath`;
+// `ok` is false if environment variable is not set, or empty
+func installPathEnv() (path string, ok bool, err error) {
+	if path = os.Getenv(EnvInstallPath); path == "" {
+		return "", false, nil
+	}
+
+	if !isSteamDir(path) {
+		return "", true, fmt.Errorf("GetSteamInstallPath() %s=%s is not a valid Steam directory", EnvInstallPath, path)
+	}
+
+	return filepath.Clean(path), true, nil
+}
+
+// isSteamDir returns true if given directory contains Steam libraries list
+func isSteamDir(path string) bool {
+	var fileInfo, err = os.Stat(filepath.Join(path, "steamapps", "libraryfolders.vdf"))
+
+	return err == nil && fileInfo.Mode().IsRegular()
+}
@@ -1,12 +1,56 @@
 package steam
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// installPaths returns all known Steam locations, relative to user home directory
+//
+// Native installation is usually available under more than one path (via symlinks),
+// while Flatpak and Snap packages keep everything inside their own sandbox
+func installPaths() []string {
+	return []string{
+		// native
+		filepath.Join(".steam", "steam"),
+		filepath.Join(".steam", "root"),
+		filepath.Join(".local", "share", "Steam"),
+		// Flatpak
+		filepath.Join(".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"),
+		filepath.Join(".var", "app", "com.valvesoftware.Steam", ".steam", "steam"),
+		// Snap
+		filepath.Join("snap", "steam", "common", ".local", "share", "Steam"),
+		filepath.Join("snap", "steam", "common", ".steam", "steam"),
+	}
+}
 
-// TODO: linux steam mess
 func GetSteamInstallPath() (output string, err error) {
-	// From quick research, it seems locating Steam on Linux is a TOTAL. MESS.
-	// And by mess i mean it needs a touch of someone actually using Steam on Linux,
-	// as it's hilarious disaster
+	var ok bool
+	if output, ok, err = installPathEnv(); ok {
+		return output, err
+	}
+
+	var home string
+	if home, err = os.UserHomeDir(); err != nil {
+		return "", fmt.Errorf("GetSteamInstallPath() cannot find home directory: %w", err)
+	}
+
+	for _, installPath := range installPaths() {
+		var path = filepath.Join(home, installPath)
+
+		// double check if it really is valid install directory
+		if !isSteamDir(path) {
+			continue
+		}
+
+		// prefer real location over symlinks
+		if realPath, err := filepath.EvalSymlinks(path); err == nil {
+			path = realPath
+		}
+
+		return filepath.Clean(path), nil
+	}
 
-	return "", fmt.Errorf("GetSteamInstallPath() not implemented for current platform")
+	return "", fmt.Errorf("GetSteamInstallPath() Steam not found")
 }
@@ -0,0 +1,44 @@
+package steam
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+func TestInstallPathLinux(t *testing.T) {
+	t.Setenv(EnvInstallPath, "")
+
+	for _, installPath := range installPaths() {
+		t.Run(filepath.ToSlash(installPath), func(t *testing.T) {
+			var home = t.TempDir()
+			t.Setenv("HOME", home)
+
+			_, err := GetSteamInstallPath()
+			test.Error(t, err)
+
+			var steamDir = MakeSteamDir(t, filepath.Join(home, installPath), nil)
+
+			var path string
+			path, err = GetSteamInstallPath()
+			must.NoError(t, err)
+			test.EqOp(t, steamDir, path)
+		})
+	}
+
+	t.Run("Symlink", func(t *testing.T) {
+		var home = t.TempDir()
+		t.Setenv("HOME", home)
+
+		var steamDir = MakeSteamDir(t, filepath.Join(home, ".local", "share", "Steam"), nil)
+		must.NoError(t, os.Mkdir(filepath.Join(home, ".steam"), 0755))
+		must.NoError(t, os.Symlink(steamDir, filepath.Join(home, ".steam", "steam")))
+
+		var path, err = GetSteamInstallPath()
+		must.NoError(t, err)
+		test.EqOp(t, steamDir, path)
+	})
+}
@@ -0,0 +1,61 @@
+package steam
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+// MakeSteamDir creates fake Steam directory with single library, containing given apps
+//
+// `apps` keys are app IDs, values are install directories names
+func MakeSteamDir(tb testing.TB, steamDir string, apps map[uint64]string) string {
+	tb.Helper()
+
+	var steamApps = filepath.Join(steamDir, "steamapps")
+	must.NoError(tb, os.MkdirAll(steamApps, 0755))
+
+	var vdf strings.Builder
+	fmt.Fprintf(&vdf, "\"libraryfolders\"\n{\n\t\"0\"\n\t{\n\t\t\"path\"\t\t%q\n\t\t\"apps\"\n\t\t{\n", steamDir)
+
+	for appID, installDir := range apps {
+		fmt.Fprintf(&vdf, "\t\t\t\"%d\"\t\t\"0\"\n", appID)
+
+		var acf = fmt.Sprintf("\"AppState\"\n{\n\t\"appid\"\t\t\"%d\"\n\t\"installdir\"\t\t%q\n}\n", appID, installDir)
+		must.NoError(tb, os.WriteFile(filepath.Join(steamApps, fmt.Sprintf("appmanifest_%d.acf", appID)), []byte(acf), 0644))
+		must.NoError(tb, os.MkdirAll(filepath.Join(steamApps, "common", installDir), 0755))
+	}
+
+	vdf.WriteString("\t\t}\n\t}\n}\n")
+	must.NoError(tb, os.WriteFile(filepath.Join(steamApps, "libraryfolders.vdf"), []byte(vdf.String()), 0644))
+
+	return steamDir
+}
+
+func TestInstallPathEnv(t *testing.T) {
+	var steamDir = MakeSteamDir(t, t.TempDir(), map[uint64]string{AppID.Fallout2: "Fallout 2"})
+	t.Setenv(EnvInstallPath, steamDir)
+
+	var path, err = GetSteamInstallPath()
+	must.NoError(t, err)
+	test.EqOp(t, steamDir, path)
+
+	var appPath string
+	appPath, err = GetAppPath(AppID.Fallout2)
+	must.NoError(t, err)
+	test.EqOp(t, filepath.Join(steamDir, "steamapps", "common", "Fallout 2"), appPath)
+
+	test.False(t, IsSteamAppInstalled(AppID.Fallout1))
+
+	// override must point to valid Steam directory, other locations are not checked
+	t.Setenv(EnvInstallPath, t.TempDir())
+
+	_, err = GetSteamInstallPath()
+	test.Error(t, err)
+	test.False(t, IsSteamInstalled())
+}
@@ -17,6 +17,11 @@ func registryPaths() []string {
 }
 
 func GetSteamInstallPath() (output string, err error) {
+	var ok bool
+	if output, ok, err = installPathEnv(); ok {
+		return output, err
+	}
+
 	for _, registryPath := range registryPaths() {
 		var (
 			key     registry.Key
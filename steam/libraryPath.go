@@ -4,54 +4,39 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-
-	"github.com/Jleagle/steam-go/steamvdf"
+	"slices"
 )
 
 // GetLibrariesPaths returns list of all valid Steam libraries
 func GetLibrariesPaths() (output []string, err error) {
-	const (
-		self                = "GetLibrariesPaths()"
-		librariesVdf string = "libraryfolders.vdf"
-	)
+	const self = "GetLibrariesPaths()"
+
 	var (
-		steamDir       string
-		libraryFolders steamvdf.KeyValue
+		steamDir  string
+		libraries []Library
 	)
 
-	output = make([]string, 0)
-
 	// get <steam path>
 	if steamDir, err = GetSteamInstallPath(); err != nil {
 		return nil, err
 	}
 
 	// read <steam path>/steamapps/libraryfolders.vdf
-	if libraryFolders, err = steamvdf.ReadFile(filepath.Join(steamDir, "steamapps", librariesVdf)); err != nil {
-		return nil, fmt.Errorf("%s cannot read %s", self, librariesVdf)
+	if libraries, err = ReadLibraries(os.DirFS(steamDir)); err != nil {
+		return nil, fmt.Errorf("%s %w", self, err)
 	}
 
-	libraryFolders.SortChildren()
+	// older Steam versions do not list <steam path>
+	output = []string{steamDir}
 
-	// iterate all known libraries by index
-
-	for idxString := range libraryFolders.GetChildrenAsMap() {
-		var (
-			kvTemp steamvdf.KeyValue
-			ok     bool
-		)
-
-		if kvTemp, ok = libraryFolders.GetChild(idxString); !ok {
-			continue
-		}
-
-		// extract <library path>
-
-		if kvTemp, ok = kvTemp.GetChild("path"); !ok {
-			continue
-		}
+	// same library might be listed using different path (case, symlinks, etc.)
+	var outputInfo []os.FileInfo
+	if fileInfo, err := os.Stat(steamDir); err == nil {
+		outputInfo = append(outputInfo, fileInfo)
+	}
 
-		var path = filepath.Clean(kvTemp.Value)
+	for _, library := range libraries {
+		var path = filepath.Clean(library.Path)
 
 		// validate
 
@@ -60,15 +45,12 @@ func GetLibrariesPaths() (output []string, err error) {
 			continue
 		}
 
-		if !fileInfo.Mode().IsDir() {
+		if !fileInfo.Mode().IsDir() || slices.ContainsFunc(outputInfo, func(info os.FileInfo) bool { return os.SameFile(info, fileInfo) }) {
 			continue
 		}
 
 		output = append(output, path)
-	}
-
-	if len(output) < 1 {
-		return nil, fmt.Errorf("%s cannot find valid Steam library", self)
+		outputInfo = append(outputInfo, fileInfo)
 	}
 
 	return output, nil
@@ -0,0 +1,37 @@
+package steam
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+func TestLibrariesPathsLinux(t *testing.T) {
+	var (
+		home     = t.TempDir()
+		steamDir = MakeSteamDir(t, filepath.Join(home, "Steam"), nil)
+		library  = filepath.Join(home, "SteamLibrary")
+		symlink  = filepath.Join(home, "steam")
+	)
+
+	must.NoError(t, os.Mkdir(library, 0755))
+	must.NoError(t, os.Symlink(steamDir, symlink))
+
+	// same libraries listed multiple times, using different paths
+	var vdf = "\"libraryfolders\"\n{\n"
+	for idx, path := range []string{steamDir, symlink, library, library + "/", filepath.Join(home, "missing")} {
+		vdf += fmt.Sprintf("\t\"%d\"\n\t{\n\t\t\"path\"\t\t%q\n\t}\n", idx, path)
+	}
+	vdf += "}\n"
+
+	must.NoError(t, os.WriteFile(filepath.Join(steamDir, "steamapps", "libraryfolders.vdf"), []byte(vdf), 0644))
+	t.Setenv(EnvInstallPath, steamDir)
+
+	var paths, err = GetLibrariesPaths()
+	must.NoError(t, err)
+	test.Eq(t, []string{steamDir, library}, paths)
+}
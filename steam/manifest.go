@@ -0,0 +1,269 @@
+package steam
+
+import (
+	"cmp"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jleagle/steam-go/steamvdf"
+)
+
+// Library describes single entry of <steam path>/steamapps/libraryfolders.vdf
+type Library struct {
+	Path      string           // as saved in libraryfolders.vdf, might not exist on current machine
+	Label     string           // empty for default library
+	TotalSize int64            // size of drive containing library, 0 if unknown
+	Apps      map[uint64]int64 // installed apps IDs and their sizes (0 if unknown); empty for older Steam versions
+}
+
+// AppManifest describes <library path>/steamapps/appmanifest_<appID>.acf
+type AppManifest struct {
+	AppID       uint64
+	Name        string
+	InstallDir  string // relative to <library path>/steamapps/common
+	BuildID     uint64
+	LastUpdated time.Time
+	SizeOnDisk  int64
+	Depots      []Depot // sorted by ID
+}
+
+// Depot describes single entry of `InstalledDepots` in app manifest
+type Depot struct {
+	ID       uint64
+	Manifest string
+	Size     int64
+}
+
+// ReadLibraries returns all libraries listed in libraryfolders.vdf, without checking if they exist
+//
+// `fsys` must be a Steam root directory, see `GetSteamInstallPath()`. Older Steam versions
+// do not include root directory in libraryfolders.vdf, it needs to be added by caller if needed.
+// Invalid sizes are treated as unknown, and apps with invalid IDs are skipped; only unreadable
+// libraryfolders.vdf is an error
+func ReadLibraries(fsys fs.FS) (libraries []Library, err error) {
+	const self = "ReadLibraries()"
+
+	var root steamvdf.KeyValue
+	if root, err = vdfReadFile(fsys, path.Join("steamapps", "libraryfolders.vdf")); err != nil {
+		return nil, fmt.Errorf("%s %w", self, err)
+	}
+
+	// entries are indexed by number, other keys are unrelated to libraries
+	var children = slices.Clone(root.Children)
+	slices.SortStableFunc(children, func(a steamvdf.KeyValue, b steamvdf.KeyValue) int {
+		var idxA, _ = strconv.Atoi(a.Key)
+		var idxB, _ = strconv.Atoi(b.Key)
+
+		return cmp.Compare(idxA, idxB)
+	})
+
+	for _, child := range children {
+		if _, err = strconv.ParseUint(child.Key, 10, 32); err != nil {
+			continue
+		}
+
+		var library = Library{Path: child.Value, Apps: make(map[uint64]int64)}
+
+		// older Steam versions keep path directly as a value
+		if len(child.Children) > 0 {
+			library.Path = vdfString(child, "path")
+			library.Label = vdfString(child, "label")
+
+			// sizes are informational only, invalid values are treated as unknown
+			library.TotalSize, _ = vdfInt(child, "totalsize")
+
+			var apps, _ = vdfChild(child, "apps")
+			for _, app := range apps.Children {
+				var appID uint64
+				if appID, err = strconv.ParseUint(app.Key, 10, 64); err != nil {
+					continue
+				}
+
+				library.Apps[appID], _ = vdfInt(apps, app.Key)
+			}
+		}
+
+		if library.Path == "" {
+			continue
+		}
+
+		libraries = append(libraries, library)
+	}
+
+	return libraries, nil
+}
+
+// ReadAppManifest returns manifest of app installed in given library
+//
+// `fsys` must be a library directory, see `Library.Path`
+func ReadAppManifest(fsys fs.FS, appID uint64) (manifest AppManifest, err error) {
+	var self = fmt.Sprintf("ReadAppManifest(%d)", appID)
+
+	var root steamvdf.KeyValue
+	if root, err = vdfReadFile(fsys, path.Join("steamapps", fmt.Sprintf("appmanifest_%d.acf", appID))); err != nil {
+		return AppManifest{}, fmt.Errorf("%s %w", self, err)
+	}
+
+	if manifest, err = parseAppManifest(root, appID); err != nil {
+		return AppManifest{}, fmt.Errorf("%s %w", self, err)
+	}
+
+	return manifest, nil
+}
+
+// ReadAppManifests returns manifests of all apps installed in given library, sorted by app ID
+//
+// `fsys` must be a library directory, see `Library.Path`. Manifests which cannot be read
+// by `ReadAppManifest()` are skipped
+func ReadAppManifests(fsys fs.FS) (manifests []AppManifest, err error) {
+	const self = "ReadAppManifests()"
+
+	var filenames []string
+	if filenames, err = fs.Glob(fsys, path.Join("steamapps", "appmanifest_*.acf")); err != nil {
+		return nil, fmt.Errorf("%s %w", self, err)
+	}
+
+	for _, filename := range filenames {
+		var appID uint64
+		if appID, err = strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(path.Base(filename), "appmanifest_"), ".acf"), 10, 64); err != nil {
+			continue
+		}
+
+		// single broken manifest does not affect other apps
+		var root steamvdf.KeyValue
+		if root, err = vdfReadFile(fsys, filename); err != nil {
+			continue
+		}
+
+		var manifest AppManifest
+		if manifest, err = parseAppManifest(root, appID); err != nil {
+			continue
+		}
+
+		manifests = append(manifests, manifest)
+	}
+
+	slices.SortFunc(manifests, func(a AppManifest, b AppManifest) int {
+		return cmp.Compare(a.AppID, b.AppID)
+	})
+
+	return manifests, nil
+}
+
+// InstallPath returns app directory inside given library
+func (manifest AppManifest) InstallPath(libraryPath string) string {
+	return filepath.Join(libraryPath, "steamapps", "common", manifest.InstallDir)
+}
+
+// parseAppManifest converts manifest content; `appID` is taken from manifest filename
+//
+// Only `installdir` is required; other values are informational only, invalid values are
+// treated as unknown, and depots with invalid IDs are skipped
+func parseAppManifest(root steamvdf.KeyValue, appID uint64) (manifest AppManifest, err error) {
+	if manifest.AppID, _ = vdfUint(root, "appid"); manifest.AppID == 0 {
+		manifest.AppID = appID
+	} else if manifest.AppID != appID {
+		return AppManifest{}, fmt.Errorf("app ID mismatch (%d)", manifest.AppID)
+	}
+
+	manifest.Name = vdfString(root, "name")
+
+	if manifest.InstallDir = vdfString(root, "installdir"); manifest.InstallDir == "" {
+		return AppManifest{}, fmt.Errorf("missing installdir")
+	}
+
+	manifest.BuildID, _ = vdfUint(root, "buildid")
+
+	if value, _ := vdfInt(root, "LastUpdated"); value > 0 {
+		manifest.LastUpdated = time.Unix(value, 0).UTC()
+	}
+
+	manifest.SizeOnDisk, _ = vdfInt(root, "SizeOnDisk")
+
+	var depots, _ = vdfChild(root, "InstalledDepots")
+	for _, child := range depots.Children {
+		var depot = Depot{Manifest: vdfString(child, "manifest")}
+
+		if depot.ID, err = strconv.ParseUint(child.Key, 10, 64); err != nil {
+			continue
+		}
+
+		depot.Size, _ = vdfInt(child, "size")
+
+		manifest.Depots = append(manifest.Depots, depot)
+	}
+
+	slices.SortFunc(manifest.Depots, func(a Depot, b Depot) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return manifest, nil
+}
+
+//
+
+func vdfReadFile(fsys fs.FS, filename string) (root steamvdf.KeyValue, err error) {
+	var data []byte
+	if data, err = fs.ReadFile(fsys, filename); err != nil {
+		return root, err
+	}
+
+	if root, err = steamvdf.ReadBytes(data); err != nil {
+		return root, fmt.Errorf("cannot parse %s: %w", path.Base(filename), err)
+	}
+
+	return root, nil
+}
+
+// vdfChild works same as `steamvdf.KeyValue.GetChild()`, but keys are case-insensitive, same as in Steam
+func vdfChild(kv steamvdf.KeyValue, key string) (child steamvdf.KeyValue, ok bool) {
+	var idx = slices.IndexFunc(kv.Children, func(child steamvdf.KeyValue) bool {
+		return strings.EqualFold(child.Key, key)
+	})
+
+	if idx < 0 {
+		return child, false
+	}
+
+	return kv.Children[idx], true
+}
+
+func vdfString(kv steamvdf.KeyValue, key string) string {
+	var child, _ = vdfChild(kv, key)
+
+	return child.Value
+}
+
+// vdfInt returns integer value of given key, or 0 if key does not exist
+func vdfInt(kv steamvdf.KeyValue, key string) (value int64, err error) {
+	var child, ok = vdfChild(kv, key)
+	if !ok || child.Value == "" {
+		return 0, nil
+	}
+
+	if value, err = strconv.ParseInt(child.Value, 10, 64); err != nil {
+		return 0, fmt.Errorf("invalid %s value '%s'", key, child.Value)
+	}
+
+	return value, nil
+}
+
+// vdfUint returns unsigned integer value of given key, or 0 if key does not exist
+func vdfUint(kv steamvdf.KeyValue, key string) (value uint64, err error) {
+	var child, ok = vdfChild(kv, key)
+	if !ok || child.Value == "" {
+		return 0, nil
+	}
+
+	if value, err = strconv.ParseUint(child.Value, 10, 64); err != nil {
+		return 0, fmt.Errorf("invalid %s value '%s'", key, child.Value)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,164 @@
+package steam
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+var manifestFallout2 = AppManifest{
+	AppID:       AppID.Fallout2,
+	Name:        "Fallout 2: A Post Nuclear Role Playing Game",
+	InstallDir:  "Fallout 2",
+	BuildID:     1183427,
+	LastUpdated: time.Unix(1700000000, 0).UTC(),
+	SizeOnDisk:  497213702,
+	Depots: []Depot{
+		{ID: 38411, Manifest: "6474925468424815286", Size: 497213702},
+		{ID: 228983, Manifest: "8124929965194586177", Size: 0},
+	},
+}
+
+func TestReadLibraries(t *testing.T) {
+	var libraries, err = ReadLibraries(os.DirFS(filepath.Join("testdata", "steam")))
+	must.NoError(t, err)
+	test.Eq(t, []Library{
+		{
+			Path: "/home/user/.local/share/Steam",
+			Apps: map[uint64]int64{228980: 438383604, AppID.Fallout2: 497213702},
+		},
+		{
+			Path:      "/mnt/games/SteamLibrary",
+			Label:     "Games",
+			TotalSize: 1000186310656,
+			Apps:      map[uint64]int64{AppID.Fallout1: 604180398},
+		},
+	}, libraries)
+
+	// older format, without root directory and apps list
+	libraries, err = ReadLibraries(os.DirFS(filepath.Join("testdata", "legacy")))
+	must.NoError(t, err)
+	test.Eq(t, []Library{
+		{Path: "/mnt/games/SteamLibrary", Apps: map[uint64]int64{}},
+		{Path: "/mnt/other/SteamLibrary", Apps: map[uint64]int64{}},
+	}, libraries)
+
+	_, err = ReadLibraries(fstest.MapFS{})
+	test.Error(t, err)
+
+	// invalid optional values are ignored, without affecting other values
+	for name, content := range map[string]string{
+		"TotalSize": `"libraryfolders" { "0" { "path" "/steam" "totalsize" "big" "apps" { "38410" "1" } } }`,
+		"AppID":     `"libraryfolders" { "0" { "path" "/steam" "apps" { "fallout" "1" "38410" "1" } } }`,
+		"AppSize":   `"libraryfolders" { "0" { "path" "/steam" "apps" { "38400" "-" "38410" "1" } } }`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var fsys = fstest.MapFS{
+				"steamapps/libraryfolders.vdf": &fstest.MapFile{Data: []byte(content)},
+			}
+
+			var libraries, err = ReadLibraries(fsys)
+			must.NoError(t, err)
+			must.SliceLen(t, 1, libraries)
+			test.EqOp(t, "/steam", libraries[0].Path)
+			test.Zero(t, libraries[0].TotalSize)
+			test.EqOp(t, 1, libraries[0].Apps[AppID.Fallout2])
+
+			if name == "AppSize" {
+				test.MapContainsKey(t, libraries[0].Apps, AppID.Fallout1)
+				test.Zero(t, libraries[0].Apps[AppID.Fallout1])
+			} else {
+				test.MapLen(t, 1, libraries[0].Apps)
+			}
+		})
+	}
+}
+
+func TestReadAppManifest(t *testing.T) {
+	var fsys = os.DirFS(filepath.Join("testdata", "steam"))
+
+	var manifest, err = ReadAppManifest(fsys, AppID.Fallout2)
+	must.NoError(t, err)
+	test.Eq(t, manifestFallout2, manifest)
+	test.EqOp(t, filepath.Join("/mnt", "steamapps", "common", "Fallout 2"), manifest.InstallPath("/mnt"))
+
+	_, err = ReadAppManifest(fsys, AppID.Fallout1)
+	test.Error(t, err)
+
+	var manifests []AppManifest
+	manifests, err = ReadAppManifests(fsys)
+	must.NoError(t, err)
+	test.Eq(t, []AppManifest{manifestFallout2}, manifests)
+
+	// minimal manifest, app ID taken from filename
+	manifest, err = ReadAppManifest(fstest.MapFS{
+		"steamapps/appmanifest_38400.acf": &fstest.MapFile{Data: []byte(`"AppState" { "installdir" "Fallout" }`)},
+	}, AppID.Fallout1)
+	must.NoError(t, err)
+	test.Eq(t, AppManifest{AppID: AppID.Fallout1, InstallDir: "Fallout"}, manifest)
+
+	for name, content := range map[string]string{
+		"AppID":      `"AppState" { "appid" "38410" "installdir" "Fallout" }`,
+		"InstallDir": `"AppState" { "appid" "38400" }`,
+		"Syntax":     `"AppState" {`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var fsys = fstest.MapFS{
+				"steamapps/appmanifest_38400.acf": &fstest.MapFile{Data: []byte(content)},
+				"steamapps/appmanifest_38410.acf": &fstest.MapFile{Data: []byte(`"AppState" { "installdir" "Fallout 2" }`)},
+			}
+
+			var _, err = ReadAppManifest(fsys, AppID.Fallout1)
+			test.Error(t, err)
+
+			// other manifests are still read
+			var manifests []AppManifest
+			manifests, err = ReadAppManifests(fsys)
+			must.NoError(t, err)
+			test.Eq(t, []AppManifest{{AppID: AppID.Fallout2, InstallDir: "Fallout 2"}}, manifests)
+		})
+	}
+
+	// invalid optional values are ignored, without affecting other values
+	for name, content := range map[string]string{
+		"AppIDInvalid": `"AppState" { "appid" "fallout" "installdir" "Fallout" }`,
+		"BuildID":      `"AppState" { "installdir" "Fallout" "buildid" "-1" }`,
+		"LastUpdated":  `"AppState" { "installdir" "Fallout" "LastUpdated" "yesterday" }`,
+		"SizeOnDisk":   `"AppState" { "installdir" "Fallout" "SizeOnDisk" "1GB" }`,
+		"DepotID":      `"AppState" { "installdir" "Fallout" "InstalledDepots" { "depot" { "size" "0" } "38401" { "size" "1" } } }`,
+		"DepotSize":    `"AppState" { "installdir" "Fallout" "InstalledDepots" { "38401" { "size" "?" } } }`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var fsys = fstest.MapFS{
+				"steamapps/appmanifest_38400.acf": &fstest.MapFile{Data: []byte(content)},
+			}
+
+			var manifest, err = ReadAppManifest(fsys, AppID.Fallout1)
+			must.NoError(t, err)
+			test.EqOp(t, AppID.Fallout1, manifest.AppID)
+			test.EqOp(t, "Fallout", manifest.InstallDir)
+			test.Zero(t, manifest.BuildID)
+			test.True(t, manifest.LastUpdated.IsZero())
+			test.Zero(t, manifest.SizeOnDisk)
+
+			switch name {
+			case "DepotID":
+				test.Eq(t, []Depot{{ID: 38401, Size: 1}}, manifest.Depots)
+			case "DepotSize":
+				test.Eq(t, []Depot{{ID: 38401}}, manifest.Depots)
+			default:
+				test.SliceEmpty(t, manifest.Depots)
+			}
+
+			var manifests []AppManifest
+			manifests, err = ReadAppManifests(fsys)
+			must.NoError(t, err)
+			test.Eq(t, []AppManifest{manifest}, manifests)
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package vfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wipe2238/fo/config"
+)
+
+// SearchPathEntry is a single .dat file or directory used by engine
+type SearchPathEntry struct {
+	Path  string
+	IsDat bool
+}
+
+// SearchPath returns .dat files and directories used by game installed in `gameDir`,
+// in order they are searched by engine (highest priority first)
+//
+// Game version is detected by configuration file name, fallout2.cfg (Fallout 2) or fallout.cfg (Fallout 1).
+// Configuration uses `master_dat`, `master_patches`, `critter_dat`, `critter_patches` from `[system]` section,
+// with same default values as engine, used when key is missing; key with empty value disables given
+// .dat file or directory. Fallout 2 also loads all existing patch000.dat ... patch999.dat files,
+// with higher numbers taking precedence.
+// Relative paths are resolved case-insensitively; missing directories are skipped.
+func SearchPath(gameDir string) (entries []SearchPathEntry, err error) {
+	const errPrefix = errPackage + " SearchPath()"
+
+	var (
+		game     uint8
+		filename string
+		cfg      config.Config
+	)
+
+	for _, cfgFile := range []struct {
+		name string
+		game uint8
+	}{{"fallout2.cfg", 2}, {"fallout.cfg", 1}} {
+		if filename, err = searchPathResolve(gameDir, cfgFile.name); err == nil {
+			game = cfgFile.game
+			break
+		}
+	}
+
+	if game == 0 {
+		return nil, fmt.Errorf("%s cannot find fallout.cfg or fallout2.cfg in '%s'", errPrefix, gameDir)
+	}
+
+	if cfg, err = config.ReadFile(filename); err != nil {
+		return nil, fmt.Errorf("%s %w", errPrefix, err)
+	}
+
+	// Engine opens .dat file first, followed by its patches directory; every newly opened entry is placed
+	// on top of search path, and opening an entry which is already on a list moves it to the top
+	var add = func(value string, isDat bool) error {
+		if value == "" {
+			return nil
+		}
+
+		var filename, err = searchPathResolve(gameDir, value)
+		if err != nil {
+			if isDat {
+				return fmt.Errorf("%s cannot find '%s': %w", errPrefix, value, err)
+			}
+
+			return nil
+		}
+
+		entries = slices.DeleteFunc(entries, func(entry SearchPathEntry) bool {
+			return entry.Path == filename
+		})
+		entries = slices.Insert(entries, 0, SearchPathEntry{Path: filename, IsDat: isDat})
+
+		return nil
+	}
+
+	// Engine defaults are used only for missing keys; empty value disables given entry
+	var get = func(key string, defaultValue string) string {
+		if value, ok := cfg.Get("system", key); ok {
+			return value
+		}
+
+		return defaultValue
+	}
+
+	for _, prefix := range []string{"master", "critter"} {
+		if err = add(get(prefix+"_dat", prefix+".dat"), true); err != nil {
+			return nil, err
+		}
+
+		if err = add(get(prefix+"_patches", "data"), false); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fallout 2 opens patch000.dat ... patch999.dat, skipping missing files
+	if game == 2 {
+		var dirEntries []os.DirEntry
+		if dirEntries, err = os.ReadDir(gameDir); err != nil {
+			return nil, fmt.Errorf("%s %w", errPrefix, err)
+		}
+
+		var patches = make(map[int]string)
+		for _, dirEntry := range dirEntries {
+			var (
+				name  = strings.ToLower(dirEntry.Name())
+				index int
+			)
+
+			if len(name) != len("patch000.dat") || !strings.HasPrefix(name, "patch") || !strings.HasSuffix(name, ".dat") {
+				continue
+			} else if index, err = strconv.Atoi(name[5:8]); err != nil || name[5] == '+' || name[5] == '-' {
+				continue
+			}
+
+			// exact name takes precedence, same as in `resolve()`
+			if _, ok := patches[index]; !ok || dirEntry.Name() == name {
+				patches[index] = dirEntry.Name()
+			}
+		}
+
+		for index := range 1000 {
+			if name, ok := patches[index]; ok {
+				if err = add(name, true); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// OpenGame returns `VFS` with all .dat files and directories used by game installed in `gameDir`
+//
+// See `SearchPath()` for details
+func OpenGame(gameDir string) (vfs *VFS, err error) {
+	var entries []SearchPathEntry
+	if entries, err = SearchPath(gameDir); err != nil {
+		return nil, err
+	}
+
+	vfs = New()
+
+	for idx, entry := range entries {
+		var priority = len(entries) - idx
+
+		if !entry.IsDat {
+			vfs.AddDir(entry.Path, priority)
+			continue
+		}
+
+		if err = vfs.OpenDat(entry.Path, priority); err != nil {
+			vfs.Close()
+			return nil, err
+		}
+	}
+
+	return vfs, nil
+}
+
+// searchPathResolve converts path used in configuration file to existing file/directory path
+func searchPathResolve(gameDir string, value string) (filename string, err error) {
+	value = filepath.FromSlash(strings.ReplaceAll(value, `\`, "/"))
+
+	var name = filepath.ToSlash(filepath.Clean(value))
+
+	// Paths outside of game directory are used as-is
+	if filepath.IsAbs(value) || name == ".." || strings.HasPrefix(name, "../") {
+		if !filepath.IsAbs(value) {
+			value = filepath.Join(gameDir, value)
+		}
+
+		if _, err = os.Stat(value); err != nil {
+			return "", err
+		}
+
+		return filepath.Clean(value), nil
+	}
+
+	if name, err = resolve(os.DirFS(gameDir), name); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(gameDir, filepath.FromSlash(name)), nil
+}
@@ -0,0 +1,160 @@
+package vfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+// MakeGameDir creates game directory with given configuration file and .dat files
+func MakeGameDir(tb testing.TB, cfgName string, cfg string, dats map[string]map[string]string, dirs ...string) (gameDir string) {
+	tb.Helper()
+
+	gameDir = tb.TempDir()
+
+	if cfgName != "" {
+		must.NoError(tb, os.WriteFile(filepath.Join(gameDir, cfgName), []byte(cfg), 0644))
+	}
+
+	for name, files := range dats {
+		must.NoError(tb, os.Rename(MakeDat(tb, files), filepath.Join(gameDir, name)))
+	}
+
+	for _, dir := range dirs {
+		must.NoError(tb, os.MkdirAll(filepath.Join(gameDir, dir), 0755))
+	}
+
+	return gameDir
+}
+
+func TestSearchPath(t *testing.T) {
+	var dats = map[string]map[string]string{
+		"MASTER.DAT":   {"TEXT/ENGLISH/GAME/MISC.MSG": "master", "MAPS/ARTEMPLE.MAP": "master"},
+		"critter.dat":  {"ART/CRITTERS/HAPOWERA.FRM": "critter"},
+		"patch000.dat": {"MAPS/ARTEMPLE.MAP": "patch"},
+	}
+
+	t.Run("Fallout2", func(t *testing.T) {
+		var gameDir = MakeGameDir(t, "fallout2.cfg", "[system]\nmaster_dat=master.dat\nmaster_patches=data\ncritter_dat=critter.dat\ncritter_patches=data\n", dats, "DATA")
+
+		var entries, err = SearchPath(gameDir)
+		must.NoError(t, err)
+		test.Eq(t, []SearchPathEntry{
+			{filepath.Join(gameDir, "patch000.dat"), true},
+			{filepath.Join(gameDir, "DATA"), false},
+			{filepath.Join(gameDir, "critter.dat"), true},
+			{filepath.Join(gameDir, "MASTER.DAT"), true},
+		}, entries)
+
+		must.NoError(t, os.WriteFile(filepath.Join(gameDir, "DATA", "misc.msg"), []byte("data"), 0644))
+		must.NoError(t, os.MkdirAll(filepath.Join(gameDir, "DATA", "maps"), 0755))
+		must.NoError(t, os.WriteFile(filepath.Join(gameDir, "DATA", "maps", "artemple.map"), []byte("data"), 0644))
+
+		var vfs *VFS
+		vfs, err = OpenGame(gameDir)
+		must.NoError(t, err)
+		defer vfs.Close()
+
+		for name, expected := range map[string]string{
+			"maps/artemple.map":          "patch",
+			"text/english/game/misc.msg": "master",
+			"art/critters/hapowera.frm":  "critter",
+			"misc.msg":                   "data",
+		} {
+			var data, err = vfs.ReadFile(name)
+			must.NoError(t, err)
+			test.EqOp(t, expected, string(data), test.Sprint(name))
+		}
+
+		// layers order matches package documentation
+		var layers []string
+		for _, layer := range vfs.Layers() {
+			layers = append(layers, filepath.Base(layer.Name))
+		}
+		test.Eq(t, []string{"patch000.dat", "DATA", "critter.dat", "MASTER.DAT"}, layers)
+	})
+
+	t.Run("Patches", func(t *testing.T) {
+		var gameDir = MakeGameDir(t, "fallout2.cfg", "", map[string]map[string]string{
+			"master.dat":   dats["MASTER.DAT"],
+			"critter.dat":  dats["critter.dat"],
+			"patch000.dat": dats["patch000.dat"],
+			"PATCH002.DAT": {"MAPS/ARTEMPLE.MAP": "patch002"},
+			"patch010.dat": {"TEXT/ENGLISH/GAME/MISC.MSG": "patch010"},
+			"patch1.dat":   {"MAPS/ARTEMPLE.MAP": "ignored"},
+			"patch-01.dat": {"MAPS/ARTEMPLE.MAP": "ignored"},
+		}, "data")
+
+		var entries, err = SearchPath(gameDir)
+		must.NoError(t, err)
+		test.Eq(t, []SearchPathEntry{
+			{filepath.Join(gameDir, "patch010.dat"), true},
+			{filepath.Join(gameDir, "PATCH002.DAT"), true},
+			{filepath.Join(gameDir, "patch000.dat"), true},
+			{filepath.Join(gameDir, "data"), false},
+			{filepath.Join(gameDir, "critter.dat"), true},
+			{filepath.Join(gameDir, "master.dat"), true},
+		}, entries)
+
+		var vfs *VFS
+		vfs, err = OpenGame(gameDir)
+		must.NoError(t, err)
+		defer vfs.Close()
+
+		for name, expected := range map[string]string{
+			"maps/artemple.map":          "patch002",
+			"text/english/game/misc.msg": "patch010",
+		} {
+			var data, err = vfs.ReadFile(name)
+			must.NoError(t, err)
+			test.EqOp(t, expected, string(data), test.Sprint(name))
+		}
+	})
+
+	t.Run("Fallout1", func(t *testing.T) {
+		// defaults are used for missing values, empty values disable entry, patch000.dat is ignored
+		var gameDir = MakeGameDir(t, "FALLOUT.CFG", "[system]\nmaster_patches=\ncritter_patches=crit\n", dats, "data", "crit")
+
+		var entries, err = SearchPath(gameDir)
+		must.NoError(t, err)
+		test.Eq(t, []SearchPathEntry{
+			{filepath.Join(gameDir, "crit"), false},
+			{filepath.Join(gameDir, "critter.dat"), true},
+			{filepath.Join(gameDir, "MASTER.DAT"), true},
+		}, entries)
+
+		gameDir = MakeGameDir(t, "fallout.cfg", "[system]\nmaster_dat=\ncritter_dat=\n", dats, "data")
+
+		entries, err = SearchPath(gameDir)
+		must.NoError(t, err)
+		test.Eq(t, []SearchPathEntry{
+			{filepath.Join(gameDir, "data"), false},
+		}, entries)
+	})
+
+	t.Run("Absolute", func(t *testing.T) {
+		var shared = MakeGameDir(t, "", "", map[string]map[string]string{"master.dat": dats["MASTER.DAT"]})
+		var gameDir = MakeGameDir(t, "fallout2.cfg", "[system]\nmaster_dat="+filepath.Join(shared, "master.dat")+"\n", map[string]map[string]string{"critter.dat": dats["critter.dat"]})
+
+		var entries, err = SearchPath(gameDir)
+		must.NoError(t, err)
+		test.Eq(t, []SearchPathEntry{
+			{filepath.Join(gameDir, "critter.dat"), true},
+			{filepath.Join(shared, "master.dat"), true},
+		}, entries)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		var _, err = SearchPath(MakeGameDir(t, "", "", dats))
+		test.Error(t, err)
+
+		_, err = SearchPath(MakeGameDir(t, "fallout2.cfg", "[system]\nmaster_dat=missing.dat\n", dats))
+		test.Error(t, err)
+
+		_, err = OpenGame(MakeGameDir(t, "fallout2.cfg", "", map[string]map[string]string{"master.dat": dats["MASTER.DAT"]}))
+		test.Error(t, err)
+	})
+}
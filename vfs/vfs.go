@@ -0,0 +1,313 @@
+// Package vfs combines .dat files and directories into a single, case-insensitive filesystem
+//
+// Lookup works same way as in engine: each layer is searched in order of priority,
+// and first layer which contains requested path is used. Usual Fallout 2 setup, as created
+// by `OpenGame()`, is
+//
+//	patch000.dat (highest priority)
+//	data/
+//	critter.dat
+//	master.dat   (lowest priority)
+package vfs
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/wipe2238/fo/dat"
+)
+
+const errPackage = "fo/vfs:"
+
+// VFS is a stack of layers, searched in order of their priority
+//
+// Implements `fs.FS`, `fs.ReadDirFS`, `fs.ReadFileFS`, `fs.StatFS`
+type VFS struct {
+	layers  []*Layer
+	closers []io.Closer
+}
+
+// Layer is a single filesystem added to `VFS`
+type Layer struct {
+	Name     string
+	Priority int // layers with higher priority are searched first
+	FS       fs.FS
+}
+
+// vfsDir implements `fs.ReadDirFile`; entries are merged from all layers which have given directory
+type vfsDir struct {
+	name    string
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+var (
+	_ fs.FS         = (*VFS)(nil)
+	_ fs.ReadDirFS  = (*VFS)(nil)
+	_ fs.ReadFileFS = (*VFS)(nil)
+	_ fs.StatFS     = (*VFS)(nil)
+)
+
+// New returns empty `VFS`
+func New() *VFS {
+	return &VFS{layers: make([]*Layer, 0)}
+}
+
+// AddFS adds new layer
+//
+// Layers with same priority are searched in order they were added
+func (vfs *VFS) AddFS(name string, fsys fs.FS, priority int) {
+	var layer = &Layer{Name: name, Priority: priority, FS: fsys}
+
+	var idx = slices.IndexFunc(vfs.layers, func(other *Layer) bool {
+		return other.Priority < priority
+	})
+
+	if idx < 0 {
+		vfs.layers = append(vfs.layers, layer)
+	} else {
+		vfs.layers = slices.Insert(vfs.layers, idx, layer)
+	}
+}
+
+// AddDir adds directory as a new layer
+func (vfs *VFS) AddDir(dir string, priority int) {
+	vfs.AddFS(dir, os.DirFS(dir), priority)
+}
+
+// AddDat adds already opened .dat file as a new layer
+//
+// `stream` must stay open as long as `VFS` is in use, see `dat.FS()`
+func (vfs *VFS) AddDat(name string, datFile dat.FalloutDat, stream io.ReaderAt, priority int) {
+	vfs.AddFS(name, dat.FS(datFile, stream), priority)
+}
+
+// OpenDat opens .dat file and adds it as a new layer; file is closed by `Close()`
+func (vfs *VFS) OpenDat(filename string, priority int) (err error) {
+	var (
+		osFile  *os.File
+		datFile dat.FalloutDat
+	)
+
+	if osFile, datFile, err = dat.Open(filename); err != nil {
+		return fmt.Errorf("%s OpenDat() %w", errPackage, err)
+	}
+
+	vfs.closers = append(vfs.closers, osFile)
+	vfs.AddDat(filename, datFile, osFile, priority)
+
+	return nil
+}
+
+// Close closes all .dat files opened by `OpenDat()`
+func (vfs *VFS) Close() (err error) {
+	for _, closer := range vfs.closers {
+		if errClose := closer.Close(); errClose != nil && err == nil {
+			err = errClose
+		}
+	}
+
+	vfs.closers = nil
+
+	return err
+}
+
+// Layers returns all layers, in order they are searched
+func (vfs *VFS) Layers() []Layer {
+	var layers = make([]Layer, len(vfs.layers))
+	for idx, layer := range vfs.layers {
+		layers[idx] = *layer
+	}
+
+	return layers
+}
+
+// Which returns layer used for given path, and path as it's known to that layer
+func (vfs *VFS) Which(name string) (layer Layer, layerPath string, err error) {
+	var layerPtr *Layer
+	if layerPtr, layerPath, _, err = vfs.find("which", name); err != nil {
+		return Layer{}, "", err
+	}
+
+	return *layerPtr, layerPath, nil
+}
+
+// Open implements `fs.FS`
+func (vfs *VFS) Open(name string) (fs.File, error) {
+	var layer, layerPath, info, err = vfs.find("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return layer.FS.Open(layerPath)
+	}
+
+	var entries []fs.DirEntry
+	if entries, err = vfs.ReadDir(name); err != nil {
+		return nil, err
+	}
+
+	return &vfsDir{name: name, info: info, entries: entries}, nil
+}
+
+// ReadDir implements `fs.ReadDirFS`
+//
+// Entries are merged from all layers; if the same name (case-insensitive) is used by multiple layers,
+// entry from layer with highest priority is used
+func (vfs *VFS) ReadDir(name string) (entries []fs.DirEntry, err error) {
+	var info fs.FileInfo
+	if _, _, info, err = vfs.find("readdir", name); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	var seen = make(map[string]bool)
+
+	entries = make([]fs.DirEntry, 0)
+
+	for _, layer := range vfs.layers {
+		var layerPath string
+		if layerPath, err = resolve(layer.FS, name); err != nil {
+			continue
+		}
+
+		var layerEntries []fs.DirEntry
+		if layerEntries, err = fs.ReadDir(layer.FS, layerPath); err != nil {
+			// not a directory in this layer
+			continue
+		}
+
+		for _, entry := range layerEntries {
+			if seen[strings.ToUpper(entry.Name())] {
+				continue
+			}
+
+			seen[strings.ToUpper(entry.Name())] = true
+			entries = append(entries, entry)
+		}
+	}
+
+	slices.SortFunc(entries, func(a fs.DirEntry, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return entries, nil
+}
+
+// ReadFile implements `fs.ReadFileFS`
+func (vfs *VFS) ReadFile(name string) ([]byte, error) {
+	var layer, layerPath, info, err = vfs.find("readfile", name)
+	if err != nil {
+		return nil, err
+	} else if info.IsDir() {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrInvalid}
+	}
+
+	return fs.ReadFile(layer.FS, layerPath)
+}
+
+// Stat implements `fs.StatFS`
+func (vfs *VFS) Stat(name string) (fs.FileInfo, error) {
+	var _, _, info, err = vfs.find("stat", name)
+
+	return info, err
+}
+
+// find returns first layer which contains given path
+//
+// Each layer is checked using full path, so file in lower layer can be found even if one of its
+// parent directories is a file in higher layer
+func (vfs *VFS) find(op string, name string) (layer *Layer, layerPath string, info fs.FileInfo, err error) {
+	if !fs.ValidPath(name) {
+		return nil, "", nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	for _, layer = range vfs.layers {
+		if layerPath, err = resolve(layer.FS, name); err != nil {
+			continue
+		}
+
+		if info, err = fs.Stat(layer.FS, layerPath); err != nil {
+			continue
+		}
+
+		return layer, layerPath, info, nil
+	}
+
+	return nil, "", nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
+
+// resolve returns path in exactly same case as it's used by `fsys`
+//
+// Exact path is tried first, so case-insensitive filesystems (such as `dat.FalloutFS`) are not scanned
+func resolve(fsys fs.FS, name string) (resolved string, err error) {
+	if _, err = fs.Stat(fsys, name); err == nil {
+		return name, nil
+	}
+
+	resolved = "."
+
+	for _, elem := range strings.Split(name, "/") {
+		var entries []fs.DirEntry
+		if entries, err = fs.ReadDir(fsys, resolved); err != nil {
+			return "", err
+		}
+
+		var idx = slices.IndexFunc(entries, func(entry fs.DirEntry) bool {
+			return strings.EqualFold(entry.Name(), elem)
+		})
+
+		if idx < 0 {
+			return "", fs.ErrNotExist
+		}
+
+		resolved = path.Join(resolved, entries[idx].Name())
+	}
+
+	return resolved, nil
+}
+
+//
+
+// Stat implements `fs.File`
+func (dir *vfsDir) Stat() (fs.FileInfo, error) {
+	return dir.info, nil
+}
+
+// Read implements `fs.File`
+func (dir *vfsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: dir.name, Err: fs.ErrInvalid}
+}
+
+// Close implements `fs.File`
+func (dir *vfsDir) Close() error {
+	return nil
+}
+
+// ReadDir implements `fs.ReadDirFile`
+func (dir *vfsDir) ReadDir(count int) (entries []fs.DirEntry, err error) {
+	var all = dir.entries[dir.offset:]
+
+	if count <= 0 {
+		dir.offset += len(all)
+
+		return all, nil
+	}
+
+	if len(all) == 0 {
+		return nil, io.EOF
+	}
+
+	entries = all[:min(count, len(all))]
+	dir.offset += len(entries)
+
+	return entries, nil
+}
@@ -0,0 +1,127 @@
+package vfs
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/wipe2238/fo/dat"
+)
+
+// MakeDat creates DAT2 with given files, and returns path to it
+func MakeDat(tb testing.TB, files map[string]string) (filename string) {
+	tb.Helper()
+
+	var writerFiles []dat.WriterFile
+	for filePath, data := range files {
+		writerFiles = append(writerFiles, dat.WriterFileBytes(filePath, []byte(data)))
+	}
+
+	var buff bytes.Buffer
+
+	var _, err = dat.WriteFallout2(&buff, writerFiles, dat.WriterOptions{})
+	must.NoError(tb, err)
+
+	filename = filepath.Join(tb.TempDir(), "test.dat")
+	must.NoError(tb, os.WriteFile(filename, buff.Bytes(), 0644))
+
+	return filename
+}
+
+func TestVFS(t *testing.T) {
+	var vfs = New()
+	defer vfs.Close()
+
+	// data/ directory
+	var dir = t.TempDir()
+	must.NoError(t, os.MkdirAll(filepath.Join(dir, "Text", "English", "Game"), 0755))
+	must.NoError(t, os.WriteFile(filepath.Join(dir, "Text", "English", "Game", "Misc.msg"), []byte("data"), 0644))
+	must.NoError(t, os.WriteFile(filepath.Join(dir, "ddraw.ini"), []byte("ini"), 0644))
+
+	vfs.AddDir(dir, 100)
+
+	// added before patch000.dat, but with lower priority
+	must.NoError(t, vfs.OpenDat(MakeDat(t, map[string]string{
+		"TEXT/ENGLISH/GAME/MISC.MSG":  "master",
+		"TEXT/ENGLISH/GAME/WORLD.MSG": "master",
+		"ART/CRITTERS/HAPOWERA.FRM":   "master",
+		"DDRAW.INI/FILE.TXT":          "master",
+	}), 0))
+
+	must.NoError(t, vfs.OpenDat(MakeDat(t, map[string]string{
+		"TEXT/ENGLISH/GAME/WORLD.MSG": "patch",
+		"MAPS/ARTEMPLE.MAP":           "patch",
+	}), 10))
+
+	vfs.AddFS("memory", fstest.MapFS{
+		"maps/artemple.map": &fstest.MapFile{Data: []byte("memory")},
+		"maps/arcaves.map":  &fstest.MapFile{Data: []byte("memory")},
+	}, 10)
+
+	var layers = vfs.Layers()
+	must.SliceLen(t, 4, layers)
+	test.EqOp(t, dir, layers[0].Name)
+	test.EqOp(t, 100, layers[0].Priority)
+	test.EqOp(t, 10, layers[1].Priority)
+	test.EqOp(t, "memory", layers[2].Name)
+	test.EqOp(t, 0, layers[3].Priority)
+
+	for name, expected := range map[string][2]string{
+		"text/english/game/misc.msg":  {"data", dir},
+		"TEXT/ENGLISH/GAME/MISC.MSG":  {"data", dir},
+		"text/english/game/world.msg": {"patch", layers[1].Name},
+		"art/critters/hapowera.frm":   {"master", layers[3].Name},
+		"maps/artemple.map":           {"patch", layers[1].Name},
+		"MAPS/ARCAVES.MAP":            {"memory", "memory"},
+		"ddraw.ini":                   {"ini", dir},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var data, err = vfs.ReadFile(name)
+			must.NoError(t, err)
+			test.EqOp(t, expected[0], string(data))
+
+			var layer Layer
+			layer, _, err = vfs.Which(name)
+			must.NoError(t, err)
+			test.EqOp(t, expected[1], layer.Name)
+		})
+	}
+
+	// entries are merged from all layers
+	var entries, err = vfs.ReadDir("TEXT/ENGLISH/GAME")
+	must.NoError(t, err)
+	must.SliceLen(t, 2, entries)
+	test.EqOp(t, "Misc.msg", entries[0].Name())
+	test.EqOp(t, "WORLD.MSG", entries[1].Name())
+
+	// file in higher layer hides directory in lower layer when listing, but each path is still
+	// searched in all layers, same as in engine
+	_, err = vfs.ReadDir("ddraw.ini")
+	test.Error(t, err)
+	_, err = vfs.Stat("ddraw.ini/file.txt")
+	test.NoError(t, err)
+
+	for _, name := range []string{"missing.txt", "/ddraw.ini", "../ddraw.ini", "text/english/game/missing.msg"} {
+		_, err = vfs.Open(name)
+		test.Error(t, err)
+		_, _, err = vfs.Which(name)
+		test.Error(t, err)
+	}
+
+	must.NoError(t, fstest.TestFS(vfs, "ddraw.ini", "Text/English/Game/Misc.msg", "Text/English/Game/WORLD.MSG", "ART/CRITTERS/HAPOWERA.FRM", "MAPS/arcaves.map"))
+	test.NoError(t, vfs.Close())
+}
+
+func TestVFSInvalid(t *testing.T) {
+	var vfs = New()
+
+	test.Error(t, vfs.OpenDat(filepath.Join(t.TempDir(), "missing.dat"), 0))
+
+	var _, err = vfs.Open(".")
+	test.Error(t, err)
+}